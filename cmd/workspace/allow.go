@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/strickvl/workspace-colours/internal/config"
+)
+
+// runAllow trusts (allow) or stops trusting (deny) the manifest of a
+// project directory, the current one by default.
+func runAllow(name string, args []string) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fatalf("usage: workspace %s [project-dir]", name)
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		fatalf("resolving path: %v", err)
+	}
+	path := filepath.Join(absDir, config.ManifestFile)
+
+	if name == "deny" {
+		found, err := config.Deny(path)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if !found {
+			fmt.Printf("%s was not allowed\n", path)
+			return
+		}
+		fmt.Printf("Denied %s; its commands will not run\n", path)
+		return
+	}
+
+	// Parse it first, so a broken manifest isn't trusted.
+	m, err := config.LoadManifest(absDir)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if m.Path == "" {
+		fatalf("%s has no %s", absDir, config.ManifestFile)
+	}
+	if err := config.Allow(path); err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Printf("Allowed %s to run its commands, until it changes\n", path)
+}
+
+// trustCommands reports whether the commands from a manifest may run. An
+// untrusted manifest's commands are listed and, if stdin is a terminal,
+// the user is asked whether to allow it; otherwise they are skipped with a
+// warning.
+func trustCommands(manifest string, cmds []string) bool {
+	ok, err := config.Trusted(manifest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if ok {
+		return true
+	}
+
+	fmt.Fprintf(os.Stderr, "%s has not been allowed to run commands, or has changed since:\n", manifest)
+	for _, c := range cmds {
+		fmt.Fprintf(os.Stderr, "  %s\n", c)
+	}
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Allow them to run? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a == "y" || a == "yes" {
+			if err := config.Allow(manifest); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+			return true
+		}
+	}
+	fmt.Fprintf(os.Stderr, "warning: not running them; review the file and run 'workspace allow %s' to trust it\n", filepath.Dir(manifest))
+	return false
+}
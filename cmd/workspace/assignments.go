@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/strickvl/workspace-colours/internal/config"
+)
+
+func runAssignments(args []string) {
+	if len(args) == 0 {
+		assignmentsUsage()
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "export":
+		runAssignmentsExport(args[1:])
+	case "import":
+		runAssignmentsImport(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown assignments command %q\n\n", args[0])
+		assignmentsUsage()
+		os.Exit(1)
+	}
+}
+
+// runAssignmentsExport writes the assignments as a file a team can share,
+// to stdout or the file named by --output.
+func runAssignmentsExport(args []string) {
+	fs := flag.NewFlagSet("assignments export", flag.ExitOnError)
+	output := fs.StringP("output", "o", "", "write to this file instead of stdout")
+	fs.Usage = assignmentsUsage
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fatalf("usage: workspace assignments export [-o <file>]")
+	}
+
+	data, n, err := config.Export()
+	if err != nil {
+		fatalf("exporting: %v", err)
+	}
+	if *output == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fatalf("writing %s: %v", *output, err)
+	}
+	fmt.Printf("Exported %d assignment(s) to %s\n", n, *output)
+}
+
+// runAssignmentsImport merges an exported file into the assignments.
+func runAssignmentsImport(args []string) {
+	fs := flag.NewFlagSet("assignments import", flag.ExitOnError)
+	overwrite := fs.Bool("overwrite", false, "replace colours already assigned here")
+	fs.Usage = assignmentsUsage
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fatalf("usage: workspace assignments import [--overwrite] <file>")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fatalf("reading %s: %v", fs.Arg(0), err)
+	}
+	res, err := config.Import(data, *overwrite)
+	if err != nil {
+		fatalf("importing %s: %v", fs.Arg(0), err)
+	}
+
+	fmt.Printf("Added %d, replaced %d assignment(s).\n", len(res.Added), len(res.Replaced))
+	if len(res.Kept) > 0 {
+		fmt.Printf("Kept your own colour for %d project(s); use --overwrite to replace them:\n", len(res.Kept))
+		for _, key := range res.Kept {
+			fmt.Printf("  %s\n", key)
+		}
+	}
+	if len(res.Unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: skipped %d project(s) whose colour isn't in your palette:\n", len(res.Unknown))
+		for _, key := range res.Unknown {
+			fmt.Fprintf(os.Stderr, "  %s\n", key)
+		}
+	}
+	if res.NeedIdentity > 0 {
+		fmt.Fprintf(os.Stderr, "note: %d project(s) are keyed by git remote or root commit; set identity = \"remote\" in config.toml for them to apply\n", res.NeedIdentity)
+	}
+}
+
+func assignmentsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  workspace assignments export [-o <file>]            write assignments to share with a team
+  workspace assignments import [--overwrite] <file>   add assignments from an exported file
+`)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/strickvl/workspace-colours/internal/config"
+)
+
+func runConfig(args []string) {
+	if len(args) == 0 {
+		configUsage()
+		os.Exit(1)
+	}
+	switch args[0] {
+	case "migrate":
+		runConfigMigrate(args[1:])
+	case "show":
+		runConfigShow(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown config command %q\n\n", args[0])
+		configUsage()
+		os.Exit(1)
+	}
+}
+
+// runConfigMigrate upgrades the assignments and session files to the
+// current schema, or with --dry-run lists what would change.
+func runConfigMigrate(args []string) {
+	fs := flag.NewFlagSet("config migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "show what would change without writing anything")
+	fs.Usage = configUsage
+	fs.Parse(args)
+
+	if !*dryRun {
+		moved, err := config.Relocate()
+		if err != nil {
+			fatalf("relocating config: %v", err)
+		}
+		for _, m := range moved {
+			fmt.Println(m)
+		}
+	}
+	migrations, err := config.Migrate(*dryRun)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if len(migrations) == 0 {
+		fmt.Println("Config files are up to date.")
+		return
+	}
+	for _, m := range migrations {
+		if *dryRun {
+			fmt.Printf("Would upgrade %s from v%d to v%d:\n", m.Path, m.From, m.To)
+		} else {
+			fmt.Printf("Upgraded %s from v%d to v%d (backup: %s):\n", m.Path, m.From, m.To, m.Backup)
+		}
+		for _, s := range m.Steps {
+			fmt.Printf("  %s\n", s)
+		}
+	}
+}
+
+// runConfigShow prints the global config file, or with --resolved the
+// launch settings a project would get and where each one comes from.
+func runConfigShow(args []string) {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	resolved := fs.Bool("resolved", false, "show the effective launch settings for a project")
+	fs.Usage = configUsage
+	fs.Parse(args)
+
+	if !*resolved {
+		if fs.NArg() > 0 {
+			fatalf("usage: workspace config show [--resolved [project-dir]]")
+		}
+		path, data, err := config.SettingsFile()
+		if err != nil {
+			fatalf("%v", err)
+		}
+		if data == nil {
+			fmt.Printf("# %s does not exist; built-in defaults apply\n", path)
+			return
+		}
+		fmt.Printf("# %s\n", path)
+		os.Stdout.Write(data)
+		return
+	}
+
+	if fs.NArg() > 1 {
+		fatalf("usage: workspace config show --resolved [project-dir]")
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		fatalf("resolving path: %v", err)
+	}
+	// A manifest's colour may name a custom scheme.
+	loadPalettes()
+	launch, err := config.ResolveLaunch(absDir, nil)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	fmt.Printf("Launch settings for %s\n", absDir)
+	fmt.Println("(built-in < config.toml [defaults] < .workspace.toml < flags)")
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tFROM")
+	row := func(key, value string) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, value, launch.Sources[key])
+	}
+	row("terminals", fmt.Sprint(launch.TerminalCount))
+	for i, t := range toTerminals(launch.TerminalCount, launch.Terminals) {
+		value, source := t.Label, launch.Sources["terminal"]
+		if t.Command != "" {
+			value += ": " + t.Command
+		}
+		if i >= len(launch.Terminals) {
+			source = config.SourceBuiltIn
+		}
+		fmt.Fprintf(w, "terminal %d\t%s\t%s\n", i+1, value, source)
+	}
+	row("cursor", fmt.Sprint(launch.Cursor))
+	row("browser", fmt.Sprint(launch.Browser))
+	row("borders", fmt.Sprint(launch.Borders))
+	if launch.Color == "" {
+		row("color", "(assigned)")
+	} else {
+		row("color", launch.Color)
+	}
+	if len(launch.URLs) == 0 {
+		row("urls", "(none)")
+	} else {
+		row("urls", strings.Join(launch.URLs, " "))
+	}
+	w.Flush()
+}
+
+// migrateConfig moves config files from their pre-XDG location and brings
+// those written by older versions up to date before a command reads them.
+// A file from a newer version stops the command, rather than letting it
+// overwrite data it doesn't understand.
+func migrateConfig() {
+	moved, err := config.Relocate()
+	if err != nil {
+		fatalf("relocating config: %v", err)
+	}
+	for _, m := range moved {
+		fmt.Fprintln(os.Stderr, m)
+	}
+
+	migrations, err := config.Migrate(false)
+	if errors.Is(err, config.ErrNewerVersion) {
+		fatalf("%v\nupgrade workspace to use this config", err)
+	}
+	if err != nil {
+		fatalf("migrating config: %v", err)
+	}
+	for _, m := range migrations {
+		fmt.Fprintf(os.Stderr, "Upgraded %s from v%d to v%d (backup: %s)\n", m.Path, m.From, m.To, m.Backup)
+	}
+}
+
+func configUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  workspace config show                   print the global config.toml
+  workspace config show --resolved [dir]  show a project's launch settings and where each comes from
+  workspace config migrate [--dry-run]    upgrade config files written by older versions
+`)
+}
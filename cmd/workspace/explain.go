@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/strickvl/workspace-colours/internal/config"
+)
+
+// runExplain reports which colour a project gets and what decided it.
+func runExplain(args []string) {
+	if len(args) != 1 {
+		fatalf("usage: workspace explain <project-dir>")
+	}
+	absDir, err := filepath.Abs(args[0])
+	if err != nil {
+		fatalf("resolving path: %v", err)
+	}
+
+	d, err := config.Explain(absDir)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Printf("Project:  %s\n", absDir)
+	if d.InheritedFrom != "" {
+		fmt.Printf("Inherits: %s\n", d.InheritedFrom)
+	}
+	if d.Key != absDir && d.Key != d.InheritedFrom {
+		fmt.Printf("Identity: %s\n", d.Key)
+	}
+	if d.Scheme == nil {
+		fmt.Println("Color:    (none)")
+	} else {
+		fmt.Printf("Color:    %s (%s)\n", d.Scheme.Name, d.Scheme.Base)
+	}
+	fmt.Printf("Why:      %s\n", d.Reason)
+	for _, n := range d.Notes {
+		fmt.Printf("Note:     %s\n", n)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/strickvl/workspace-colours/internal/config"
+)
+
+// runInit writes a starter .workspace.toml to a project directory (the
+// current one by default).
+func runInit(args []string) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	force := fs.Bool("force", false, "overwrite an existing manifest")
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fatalf("usage: workspace init [--force] [project-dir]")
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		fatalf("resolving path: %v", err)
+	}
+	if info, err := os.Stat(absDir); err != nil || !info.IsDir() {
+		fatalf("%s is not a valid directory", absDir)
+	}
+
+	path, err := config.InitManifest(absDir, *force)
+	if err != nil {
+		fatalf("%v (use --force to replace it)", err)
+	}
+	// You wrote this one, so its commands need no review.
+	if err := config.Allow(path); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+}
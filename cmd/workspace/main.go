@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -15,21 +17,72 @@ import (
 )
 
 func main() {
+	// Config files are upgraded before anything reads them, except by
+	// "workspace config migrate", which manages migration itself.
+	if len(os.Args) < 3 || os.Args[1] != "config" || os.Args[2] != "migrate" {
+		migrateConfig()
+	}
+
+	// Subcommands with their own flags are dispatched before the global
+	// flags are parsed.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "init":
+			loadPalettes()
+			runInit(os.Args[2:])
+			return
+		case "allow", "deny":
+			loadPalettes()
+			runAllow(os.Args[1], os.Args[2:])
+			return
+		case "assignments":
+			loadPalettes()
+			runAssignments(os.Args[2:])
+			return
+		case "prune":
+			loadPalettes()
+			runPrune(os.Args[2:])
+			return
+		case "explain":
+			loadPalettes()
+			runExplain(os.Args[2:])
+			return
+		case "palette":
+			loadPalettes()
+			runPalette(os.Args[2:])
+			return
+		case "watch":
+			loadPalettes()
+			runWatch(os.Args[2:])
+			return
+		}
+	}
+
 	terminals := flag.IntP("terminals", "t", 2, "number of Ghostty terminal windows to open")
-	colorName := flag.StringP("color", "c", "", "force a specific color scheme (e.g. red, blue, green)")
+	colorName := flag.StringP("color", "c", "", "force a specific color scheme (e.g. red, blue, green) or a hex colour like '#ff8800'")
 	browser := flag.BoolP("browser", "b", false, "also launch a color-themed Firefox profile")
 	list := flag.BoolP("list", "l", false, "list all current color assignments")
+	sortBy := flag.String("sort", "name", "order --list by name, recent (last opened first) or opens (most opened first)")
 	resetColor := flag.Bool("reset-color", false, "remove the color assignment for a project")
 	noCursor := flag.Bool("no-cursor", false, "skip opening Cursor IDE")
 	noTerminals := flag.Bool("no-terminals", false, "skip opening Ghostty terminals")
 	borders := flag.Bool("borders", false, "update JankyBorders active window colour")
 	closeAll := flag.Bool("close-all", false, "close all tracked workspace windows")
+	appearance := flag.String("appearance", "", "use the dark, light or auto (follow desktop) variant for this project (remembered; \"default\" follows config.toml)")
 	flag.Usage = usage
 
 	flag.Parse()
 
+	loadPalettes()
+
+	if flag.CommandLine.Changed("sort") && !*list {
+		fatalf("--sort only applies to --list")
+	}
 	if *list {
-		runList()
+		runList(*sortBy)
 		return
 	}
 
@@ -76,15 +129,60 @@ func main() {
 		return
 	}
 
+	// Flags override the project manifest and global defaults.
+	launch, err := config.ResolveLaunch(absDir, flagOptions(*terminals, *noTerminals, *noCursor, *browser, *borders, *colorName))
+	if err != nil {
+		fatalf("%v", err)
+	}
+	// A colour from the manifest is the project's team colour, which
+	// GetOrAssign applies itself; only -c forces one.
+	forceColor := ""
+	if launch.Sources["color"] == config.SourceFlag {
+		forceColor = launch.Color
+	} else if launch.Color != "" {
+		warnReservedTeamColor(launch.Color, launch.Sources["color"])
+	}
+	// Commands in a manifest came with the repository, so they only run
+	// once it is trusted.
+	if cmds := launch.ManifestCommands(); len(cmds) > 0 && !trustCommands(launch.Manifest, cmds) {
+		launch.DropCommands()
+	}
+
 	// Get or assign a color.
-	scheme, err := config.GetOrAssign(absDir, *colorName)
+	scheme, err := config.GetOrAssign(absDir, forceColor)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	if flag.CommandLine.Changed("appearance") {
+		var a color.Appearance
+		if *appearance != "default" {
+			if a, err = color.ParseAppearance(*appearance); err != nil {
+				fatalf("%v", err)
+			}
+		}
+		if err := config.SetAppearance(absDir, a); err != nil {
+			fatalf("saving appearance: %v", err)
+		}
+	}
+	setting, err := config.AppearanceSetting(absDir)
 	if err != nil {
 		fatalf("%v", err)
 	}
+	mode, err := config.ResolveAppearance(absDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	scheme = scheme.Variant(mode)
+	followSystem := setting == color.Auto
 
 	projectName := filepath.Base(absDir)
 	fmt.Printf("Workspace: %s\n", projectName)
-	fmt.Printf("Color:     %s (%s)\n", scheme.Name, scheme.Base)
+	if scheme.IsLight() {
+		fmt.Printf("Color:     %s (%s, light)\n", scheme.Name, scheme.Base)
+	} else {
+		fmt.Printf("Color:     %s (%s)\n", scheme.Name, scheme.Base)
+	}
 	fmt.Println()
 
 	// Track all launched processes for session management.
@@ -95,13 +193,13 @@ func main() {
 	}
 
 	// Launch Firefox with themed profile.
-	if *browser {
+	if launch.Browser {
 		fmt.Println("Setting up Firefox profile...")
 		if err := launcher.EnsureFirefoxProfile(scheme); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: Firefox profile setup failed: %v\n", err)
 		} else {
 			fmt.Println("Launching Firefox...")
-			proc, err := launcher.LaunchFirefox(scheme)
+			proc, err := launcher.LaunchFirefox(scheme, launch.URLs...)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "warning: Firefox launch failed: %v\n", err)
 			} else {
@@ -111,9 +209,9 @@ func main() {
 	}
 
 	// Launch Ghostty terminals.
-	if !*noTerminals {
-		fmt.Printf("Opening %d Ghostty terminal(s)...\n", *terminals)
-		procs, err := launcher.LaunchGhostty(scheme, absDir, *terminals)
+	if launch.TerminalCount > 0 {
+		fmt.Printf("Opening %d Ghostty terminal(s)...\n", launch.TerminalCount)
+		procs, err := launcher.LaunchGhostty(scheme, absDir, toTerminals(launch.TerminalCount, launch.Terminals), followSystem)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "warning: Ghostty launch failed: %v\n", err)
 		}
@@ -123,7 +221,7 @@ func main() {
 	}
 
 	// Configure and launch Cursor.
-	if !*noCursor {
+	if launch.Cursor {
 		fmt.Println("Configuring Cursor colors...")
 		if err := launcher.ConfigureCursor(scheme, absDir); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: Cursor config failed: %v\n", err)
@@ -138,7 +236,7 @@ func main() {
 	}
 
 	// Update JankyBorders window border colour.
-	if *borders {
+	if launch.Borders {
 		fmt.Println("Updating JankyBorders...")
 		if err := launcher.UpdateBorders(scheme); err != nil {
 			fmt.Fprintf(os.Stderr, "warning: JankyBorders update failed: %v\n", err)
@@ -165,6 +263,41 @@ func toTracked(p *launcher.LaunchedProcess) config.TrackedProcess {
 	}
 }
 
+// flagOptions returns the launch settings given explicitly on the command
+// line, as the top layer for config.ResolveLaunch.
+func flagOptions(terminals int, noTerminals, noCursor, browser, borders bool, colorName string) *config.LaunchOptions {
+	o := &config.LaunchOptions{Color: colorName}
+	changed := flag.CommandLine.Changed
+	if changed("terminals") {
+		o.TerminalCount = &terminals
+	}
+	if changed("no-terminals") && noTerminals {
+		zero := 0
+		o.TerminalCount = &zero
+	}
+	if changed("no-cursor") {
+		cursor := !noCursor
+		o.Cursor = &cursor
+	}
+	if changed("browser") {
+		o.Browser = &browser
+	}
+	if changed("borders") {
+		o.Borders = &borders
+	}
+	return o
+}
+
+// toTerminals converts the configured terminal windows for the launcher,
+// padding or trimming them to count.
+func toTerminals(count int, configured []config.Terminal) []launcher.Terminal {
+	var ts []launcher.Terminal
+	for _, t := range configured {
+		ts = append(ts, launcher.Terminal{Label: t.Label, Command: t.Command})
+	}
+	return launcher.Terminals(count, ts)
+}
+
 func runClose(projectDir string) {
 	absDir, err := filepath.Abs(projectDir)
 	if err != nil {
@@ -221,7 +354,10 @@ func closeSession(s *config.Session) {
 	}
 }
 
-func runList() {
+func runList(sortBy string) {
+	if sortBy != "name" && sortBy != "recent" && sortBy != "opens" {
+		fatalf("invalid --sort %q (want name, recent or opens)", sortBy)
+	}
 	assignments, err := config.List()
 	if err != nil {
 		fatalf("listing assignments: %v", err)
@@ -234,10 +370,69 @@ func runList() {
 		return
 	}
 
+	settings, err := config.LoadSettings()
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	// Projects keyed by git identity also show where they've been opened,
+	// and members of a family are listed together when sorted by name.
+	keys := make([]string, 0, len(assignments))
+	families := make(map[string]string)
+	withPaths := false
+	for key, a := range assignments {
+		keys = append(keys, key)
+		withPaths = withPaths || len(a.Paths) > 0
+		if f := settings.FamilyOfAssignment(key, a); f != nil {
+			families[key] = f.Name
+		}
+	}
+	withFamilies := len(families) > 0
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := assignments[keys[i]], assignments[keys[j]]
+		switch {
+		case sortBy == "recent" && !a.LastUsed().Equal(b.LastUsed()):
+			return a.LastUsed().After(b.LastUsed())
+		case sortBy == "opens" && a.OpenCount != b.OpenCount:
+			return a.OpenCount > b.OpenCount
+		}
+		if fi, fj := families[keys[i]], families[keys[j]]; sortBy == "name" && fi != fj {
+			// Projects outside any family come last.
+			return fj == "" || (fi != "" && fi < fj)
+		}
+		return keys[i] < keys[j]
+	})
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "PROJECT\tCOLOR\tASSIGNED")
-	for dir, a := range assignments {
-		fmt.Fprintf(w, "%s\t%s\t%s\n", dir, a.Scheme, a.AssignedAt.Format("2006-01-02 15:04"))
+	header := "PROJECT\tCOLOR\tASSIGNED\tLAST OPENED\tOPENS"
+	if withFamilies {
+		header += "\tFAMILY"
+	}
+	if withPaths {
+		header += "\tPATHS"
+	}
+	fmt.Fprintln(w, header)
+	for _, key := range keys {
+		a := assignments[key]
+		opened := "-"
+		if !a.LastOpenedAt.IsZero() {
+			opened = a.LastOpenedAt.Format("2006-01-02 15:04")
+		}
+		if settings.Released(a) {
+			opened += " (released)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d", key, a.Scheme, a.AssignedAt.Format("2006-01-02 15:04"), opened, a.OpenCount)
+		if withFamilies {
+			family := families[key]
+			if family == "" {
+				family = "-"
+			}
+			fmt.Fprintf(w, "\t%s", family)
+		}
+		if withPaths {
+			fmt.Fprintf(w, "\t%s", strings.Join(a.Paths, ", "))
+		}
+		fmt.Fprintln(w)
 	}
 	w.Flush()
 }
@@ -249,18 +444,33 @@ Usage:
   workspace <project-dir> [flags]    launch a workspace
   workspace close <project-dir>      close all tracked windows for a project
   workspace --close-all              close all tracked workspace windows
-  workspace --list                   list all color assignments
+  workspace --list [--sort recent]   list all color assignments
+  workspace palette                  preview every colour scheme
+  workspace palette check            check the contrast of every scheme
+  workspace watch [project-dir...]   recolour workspaces when the desktop switches dark/light
+  workspace init [project-dir]       write a starter .workspace.toml manifest
+  workspace allow [project-dir]      let a project's .workspace.toml run its commands
+  workspace deny [project-dir]       stop trusting a project's .workspace.toml
+  workspace prune [--dry-run]        remove assignments for deleted or unused projects
+  workspace explain <project-dir>    show which colour a project gets and why
+  workspace assignments export       write assignments to a file to share with a team
+  workspace assignments import FILE  add assignments from an exported file
+  workspace config show --resolved   show the effective launch settings for a project
+  workspace config migrate           upgrade config files written by older versions
 
 Examples:
   workspace ~/projects/zenml                    # 2 terminals + Cursor
   workspace ~/projects/zenml --terminals 4      # 4 terminals + Cursor
   workspace ~/projects/zenml -c red             # force red color
+  workspace ~/projects/zenml -c '#ff8800'       # generate a scheme from a hex colour
   workspace ~/projects/zenml --browser           # include Firefox
   workspace ~/projects/zenml --borders           # include JankyBorders
   workspace ~/projects/zenml --no-cursor        # terminals only
+  workspace ~/projects/zenml --appearance light # light variant for this project
   workspace close ~/projects/zenml              # close the workspace
   workspace --close-all                         # close everything
   workspace ~/projects/zenml --reset-color      # unassign color
+  workspace init ~/projects/zenml               # save launch settings with the project
 
 Flags:
 `)
@@ -270,6 +480,26 @@ Available colors: %v
 `, color.Names())
 }
 
+// loadPalettes merges user-defined palettes into color.Palettes. It must run
+// before anything looks up a scheme.
+func loadPalettes() {
+	if err := config.LoadPalettes(); err != nil {
+		fatalf("loading palettes: %v", err)
+	}
+}
+
+// warnReservedTeamColor warns when a project's manifest asks for a colour
+// the [policy] in config.toml reserves, which GetOrAssign then ignores.
+func warnReservedTeamColor(name, manifest string) {
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return // GetOrAssign reports it
+	}
+	if s := color.ByName(name); s != nil && settings.Policy.IsReserved(s.Name) {
+		fmt.Fprintf(os.Stderr, "warning: ignoring team colour %s from %s: it is reserved in config.toml, so only --color gives it\n", s.Name, manifest)
+	}
+}
+
 func fatalf(format string, args ...any) {
 	fmt.Fprintf(os.Stderr, "error: "+format+"\n", args...)
 	os.Exit(1)
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/config"
+	"github.com/strickvl/workspace-colours/internal/render"
+	"github.com/strickvl/workspace-colours/internal/theme"
+)
+
+func runPalette(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		runPalettePreview(args)
+		return
+	}
+	switch args[0] {
+	case "check":
+		runPaletteCheck(args[1:])
+	case "export":
+		runPaletteExport(args[1:])
+	case "import":
+		runPaletteImport(args[1:])
+	case "render":
+		runPaletteRender(args[1:])
+	default:
+		// "workspace palette red blue" previews just those schemes.
+		if color.ByName(args[0]) != nil {
+			runPalettePreview(args)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "error: unknown palette command %q\n\n", args[0])
+		paletteUsage()
+		os.Exit(1)
+	}
+}
+
+// runPalettePreview draws every scheme as coloured swatches, along with the
+// projects assigned to it.
+func runPalettePreview(args []string) {
+	fs := flag.NewFlagSet("palette", flag.ExitOnError)
+	appearance := fs.String("appearance", "dark", "variant to show: dark or light")
+	fs.Usage = paletteUsage
+	fs.Parse(args)
+
+	a, err := color.ParseAppearance(*appearance)
+	if err != nil || a == color.Auto {
+		fatalf("invalid appearance %q (want dark or light)", *appearance)
+	}
+
+	assignments, err := config.List()
+	if err != nil {
+		fatalf("listing assignments: %v", err)
+	}
+	usedBy := make(map[string][]string)
+	for key, as := range assignments {
+		usedBy[as.Scheme] = append(usedBy[as.Scheme], as.DisplayName(key))
+	}
+	for _, projects := range usedBy {
+		sort.Strings(projects)
+	}
+
+	var schemes []*color.Scheme
+	for _, s := range schemesFromArgs(fs.Args()) {
+		schemes = append(schemes, s.Variant(a))
+	}
+	truecolor := render.Truecolor(os.Getenv("COLORTERM"))
+	if err := render.Terminal(os.Stdout, schemes, usedBy, truecolor); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+// runPaletteCheck audits the contrast of every foreground/background pair
+// the launchers emit, and exits non-zero if any falls below the level. With
+// a colour-vision deficiency (from --cvd or config.toml) it also flags
+// schemes that become hard to tell apart.
+func runPaletteCheck(args []string) {
+	fs := flag.NewFlagSet("palette check", flag.ExitOnError)
+	level := fs.String("level", "AA", "minimum WCAG contrast: AA, AAA, AA-large or a ratio like 5.5")
+	cvdFlag := fs.String("cvd", "", "also check schemes stay distinct with protanopia, deuteranopia or tritanopia (default: cvd in config.toml)")
+	fs.Parse(args)
+
+	min, err := color.ParseLevel(*level)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	cvd, err := color.ParseDeficiency(*cvdFlag)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if !fs.Changed("cvd") {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			fatalf("%v", err)
+		}
+		cvd = settings.CVD
+	}
+
+	failed := false
+	schemes := schemesFromArgs(fs.Args())
+	for _, s := range schemes {
+		issues := color.Audit(s, min)
+		if len(issues) == 0 {
+			fmt.Printf("ok    %s\n", s.Name)
+			continue
+		}
+		failed = true
+		for _, i := range issues {
+			fmt.Printf("FAIL  %s\n", i)
+		}
+	}
+	if cvd != color.NormalVision {
+		collisions := color.Collisions(schemes, cvd, color.MinDistinct)
+		if len(collisions) == 0 {
+			fmt.Printf("ok    all schemes distinct with %s\n", cvd)
+		}
+		for _, c := range collisions {
+			failed = true
+			fmt.Printf("FAIL  %s: %s\n", cvd, c)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runPaletteExport writes a scheme in another terminal or editor's theme
+// format.
+func runPaletteExport(args []string) {
+	fs := flag.NewFlagSet("palette export", flag.ExitOnError)
+	format := fs.StringP("format", "f", "", fmt.Sprintf("theme format: %s", strings.Join(theme.Formats(), ", ")))
+	appearance := fs.String("appearance", "dark", "variant to export: dark or light")
+	output := fs.StringP("output", "o", "", "write to this file instead of stdout")
+	fs.Parse(args)
+
+	if *format == "" || fs.NArg() != 1 {
+		fatalf("usage: workspace palette export --format <format> <scheme>")
+	}
+	a, err := color.ParseAppearance(*appearance)
+	if err != nil || a == color.Auto {
+		fatalf("invalid appearance %q (want dark or light)", *appearance)
+	}
+	scheme := schemesFromArgs(fs.Args())[0].Variant(a)
+
+	var buf bytes.Buffer
+	if err := theme.Export(&buf, *format, scheme); err != nil {
+		fatalf("%v", err)
+	}
+	if *output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(*output, buf.Bytes(), 0o644); err != nil {
+		fatalf("writing %s: %v", *output, err)
+	}
+	fmt.Printf("Wrote %s theme for %s to %s\n", *format, scheme.Name, *output)
+}
+
+// runPaletteImport reads another tool's theme file and saves it as a scheme
+// in the user palette file.
+func runPaletteImport(args []string) {
+	fs := flag.NewFlagSet("palette import", flag.ExitOnError)
+	format := fs.StringP("format", "f", "", fmt.Sprintf("theme format: %s (default: from the file extension)", strings.Join(theme.ImportFormats(), ", ")))
+	name := fs.StringP("name", "n", "", "scheme name (default: from the theme or file name)")
+	base := fs.String("base", "", "base colour for the accents, e.g. '#cc3333' (default: picked from the theme)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fatalf("usage: workspace palette import [--format <format>] [--name <name>] <file>")
+	}
+	path := fs.Arg(0)
+
+	if *format == "" {
+		var err error
+		if *format, err = theme.DetectFormat(path); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fatalf("reading %s: %v", path, err)
+	}
+	imported, err := theme.Import(*format, data)
+	if err != nil {
+		fatalf("importing %s: %v", path, err)
+	}
+
+	if *name == "" {
+		*name = theme.SchemeName(imported.Name)
+		if *name == "" {
+			*name = theme.SchemeName(path)
+		}
+	}
+	scheme, err := imported.Scheme(*name, *base)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	saved, err := config.AddPalette(scheme)
+	if err != nil {
+		fatalf("saving scheme: %v", err)
+	}
+	fmt.Printf("Imported %s as %q (base %s) into %s\n", path, scheme.Name, scheme.Base, saved)
+}
+
+// runPaletteRender draws schemes, or the colours of given projects, as
+// cards in an SVG or PNG image.
+func runPaletteRender(args []string) {
+	fs := flag.NewFlagSet("palette render", flag.ExitOnError)
+	out := fs.StringP("out", "o", "", "image to write; the format comes from the extension (.svg or .png)")
+	appearance := fs.String("appearance", "dark", "variant to draw: dark or light")
+	projects := fs.StringArray("project", nil, "draw this project's card, titled with its name (repeatable)")
+	fs.Parse(args)
+
+	if *out == "" {
+		fatalf("usage: workspace palette render --out <file.svg|file.png> [--project <dir>...] [scheme...]")
+	}
+	a, err := color.ParseAppearance(*appearance)
+	if err != nil || a == color.Auto {
+		fatalf("invalid appearance %q (want dark or light)", *appearance)
+	}
+
+	var write func(io.Writer, []render.Card) error
+	switch strings.ToLower(filepath.Ext(*out)) {
+	case ".svg":
+		write = render.SVG
+	case ".png":
+		write = render.PNG
+	default:
+		fatalf("unsupported image format %q (want .svg or .png)", filepath.Ext(*out))
+	}
+
+	var cards []render.Card
+	if len(*projects) > 0 {
+		if fs.NArg() > 0 {
+			fatalf("--project and scheme names cannot be combined")
+		}
+		for _, p := range *projects {
+			dir, err := filepath.Abs(p)
+			if err != nil {
+				fatalf("resolving path: %v", err)
+			}
+			scheme, err := config.Lookup(dir)
+			if err != nil {
+				fatalf("looking up %s: %v", dir, err)
+			}
+			if scheme == nil {
+				fatalf("%s has no colour assigned yet (launch it once, or pass --color)", dir)
+			}
+			cards = append(cards, render.Card{Title: filepath.Base(dir), Subtitle: scheme.Name, Scheme: scheme.Variant(a)})
+		}
+	} else {
+		var schemes []*color.Scheme
+		for _, s := range schemesFromArgs(fs.Args()) {
+			schemes = append(schemes, s.Variant(a))
+		}
+		cards = render.SchemeCards(schemes)
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, cards); err != nil {
+		fatalf("rendering: %v", err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		fatalf("writing %s: %v", *out, err)
+	}
+	fmt.Printf("Wrote %s\n", *out)
+}
+
+// schemesFromArgs resolves scheme names given on the command line, or
+// returns the whole palette if there are none.
+func schemesFromArgs(names []string) []*color.Scheme {
+	if len(names) == 0 {
+		schemes := make([]*color.Scheme, len(color.Palettes))
+		for i := range color.Palettes {
+			schemes[i] = &color.Palettes[i]
+		}
+		return schemes
+	}
+	var schemes []*color.Scheme
+	for _, n := range names {
+		s := color.ByName(n)
+		if s == nil {
+			fatalf("unknown color scheme %q (available: %v)", n, color.Names())
+		}
+		schemes = append(schemes, s)
+	}
+	return schemes
+}
+
+func paletteUsage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  workspace palette [--appearance light] [scheme...]  preview schemes as colour swatches
+  workspace palette check [--level AA] [scheme...]    check WCAG contrast of every scheme
+  workspace palette check --cvd deuteranopia          also find schemes that look alike
+  workspace palette export --format <fmt> <scheme>    write a scheme as another tool's theme
+  workspace palette import [--name <name>] <file>     add an iTerm2, base16 or Alacritty theme
+  workspace palette render --out <file> [scheme...]   draw schemes as cards in an SVG or PNG
+  workspace palette render --out <file> --project <dir>...
+                                                      draw projects in their assigned colours
+
+Export formats: %s
+`, strings.Join(theme.Formats(), ", "))
+}
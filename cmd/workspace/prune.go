@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/strickvl/workspace-colours/internal/config"
+)
+
+// runPrune removes assignments for projects that are gone or unused, and
+// their session files.
+func runPrune(args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "list what would be removed without changing anything")
+	olderThan := fs.String("older-than", "", "also prune assignments unused for this long, e.g. 90d or 12w (default: prune_after in config.toml)")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		fatalf("usage: workspace prune [--dry-run] [--older-than <age>]")
+	}
+
+	var maxAge time.Duration
+	if *olderThan != "" {
+		var err error
+		if maxAge, err = config.ParseAge(*olderThan); err != nil {
+			fatalf("%v", err)
+		}
+	} else {
+		settings, err := config.LoadSettings()
+		if err != nil {
+			fatalf("%v", err)
+		}
+		maxAge = settings.PruneAfter
+	}
+
+	res, err := config.Prune(maxAge, *dryRun)
+	if err != nil {
+		fatalf("pruning: %v", err)
+	}
+	if len(res.Assignments) == 0 && len(res.Sessions) == 0 {
+		fmt.Println("Nothing to prune.")
+		return
+	}
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+	if len(res.Assignments) > 0 {
+		fmt.Printf("%s %d assignment(s):\n", verb, len(res.Assignments))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, s := range res.Assignments {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", s.Key, s.Assignment.Scheme, s.Reason)
+		}
+		w.Flush()
+	}
+	if len(res.Sessions) > 0 {
+		fmt.Printf("%s %d session file(s):\n", verb, len(res.Sessions))
+		for _, dir := range res.Sessions {
+			fmt.Printf("  %s\n", dir)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/config"
+	"github.com/strickvl/workspace-colours/internal/desktop"
+	"github.com/strickvl/workspace-colours/internal/launcher"
+)
+
+// runWatch follows the desktop's dark/light preference, rewriting the Ghostty
+// theme and Cursor colours of every workspace set to "auto" each time it
+// flips. With no arguments it covers all workspaces with an active session.
+func runWatch(args []string) {
+	var dirs []string
+	for _, d := range args {
+		abs, err := filepath.Abs(d)
+		if err != nil {
+			fatalf("resolving path: %v", err)
+		}
+		dirs = append(dirs, abs)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	current, err := desktop.Appearance()
+	if err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Printf("Desktop appearance: %s — watching for changes (Ctrl-C to stop)\n", current)
+	applyAppearance(current, dirs)
+
+	err = desktop.Watch(ctx, func(a color.Appearance) {
+		fmt.Printf("Desktop appearance changed to %s\n", a)
+		applyAppearance(a, dirs)
+	})
+	if err != nil {
+		fatalf("%v", err)
+	}
+}
+
+// applyAppearance recolours the given workspaces, or every active session if
+// dirs is empty. Projects with a fixed dark or light setting are left alone.
+func applyAppearance(a color.Appearance, dirs []string) {
+	if len(dirs) == 0 {
+		sessions, err := config.ListSessions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: listing sessions: %v\n", err)
+		}
+		for _, s := range sessions {
+			dirs = append(dirs, s.ProjectDir)
+		}
+	}
+
+	for _, dir := range dirs {
+		setting, err := config.AppearanceSetting(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", dir, err)
+			continue
+		}
+		if setting != color.Auto {
+			continue
+		}
+		scheme, err := config.Lookup(dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", dir, err)
+			continue
+		}
+		if scheme == nil {
+			continue
+		}
+
+		variant := scheme.Variant(a)
+		fmt.Printf("  %s → %s (%s)\n", filepath.Base(dir), variant.Name, a)
+		if err := launcher.EnsureGhosttyTheme(variant); err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: Ghostty theme: %v\n", err)
+		}
+		if err := launcher.ConfigureCursor(variant, dir); err != nil {
+			fmt.Fprintf(os.Stderr, "  warning: Cursor config failed: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package color
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Appearance selects the dark or light variant of a scheme.
+type Appearance string
+
+const (
+	Dark  Appearance = "dark"
+	Light Appearance = "light"
+	// Auto follows the desktop's dark/light preference. It is a setting,
+	// not a variant: resolve it to Dark or Light before calling Variant.
+	Auto Appearance = "auto"
+)
+
+// ParseAppearance parses "dark", "light" or "auto" (case-insensitive).
+func ParseAppearance(s string) (Appearance, error) {
+	switch a := Appearance(strings.ToLower(strings.TrimSpace(s))); a {
+	case Dark, Light, Auto:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid appearance %q (want dark, light or auto)", s)
+}
+
+// IsLight reports whether s is a light variant.
+func (s *Scheme) IsLight() bool {
+	return s.Appearance == Light
+}
+
+// Variant returns the scheme for the given appearance. The built-in and
+// generated schemes are dark; their light variant keeps the same name and
+// hue and is either the hand-tuned LightVariant from a palette file or
+// generated from the base colour. Base is shared by both variants, so window
+// borders stay the same. Asking a light variant for Dark returns the scheme
+// it was derived from.
+func (s *Scheme) Variant(a Appearance) *Scheme {
+	if s.IsLight() {
+		if a == Light || s.dark == nil {
+			return s
+		}
+		return s.dark
+	}
+	if a != Light {
+		return s
+	}
+	v := lightFrom(s)
+	if s.LightVariant != nil {
+		v = s.LightVariant
+	}
+	out := *v
+	out.Name = s.Name
+	out.Appearance = Light
+	out.LightVariant = nil
+	out.dark = s
+	return &out
+}
+
+// lightFrom generates a light variant from the hue and saturation of a
+// dark scheme's base colour.
+func lightFrom(s *Scheme) *Scheme {
+	o := MustParseHex(s.Base).OKLCH()
+	v := fromHue(s.Name, o.H, chromaScale(o), lightProfile)
+	v.Base = s.Base
+	v.Appearance = Light
+	return v
+}
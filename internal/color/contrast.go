@@ -0,0 +1,163 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Level is a minimum WCAG contrast ratio.
+type Level float64
+
+// WCAG 2.x contrast thresholds. AALarge also applies to non-text elements
+// such as a terminal cursor (success criterion 1.4.11).
+const (
+	LevelAALarge Level = 3
+	LevelAA      Level = 4.5
+	LevelAAA     Level = 7
+)
+
+// ParseLevel parses "AA", "AAA" or "AA-large" (case-insensitive), or a bare
+// ratio such as "5.5".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "AA":
+		return LevelAA, nil
+	case "AAA":
+		return LevelAAA, nil
+	case "AA-LARGE", "AALARGE", "LARGE":
+		return LevelAALarge, nil
+	}
+	var f float64
+	if _, err := fmt.Sscanf(s, "%g", &f); err != nil || f < 1 || f > 21 {
+		return 0, fmt.Errorf("invalid contrast level %q (want AA, AAA, AA-large or a ratio between 1 and 21)", s)
+	}
+	return Level(f), nil
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelAA:
+		return "AA"
+	case LevelAAA:
+		return "AAA"
+	case LevelAALarge:
+		return "AA-large"
+	}
+	return fmt.Sprintf("%.1f:1", float64(l))
+}
+
+// Luminance returns the WCAG relative luminance of the colour.
+func (c RGB) Luminance() float64 {
+	return 0.2126*toLinear(c.R) + 0.7152*toLinear(c.G) + 0.0722*toLinear(c.B)
+}
+
+// Contrast returns the WCAG contrast ratio between two colours, from 1 to 21.
+func Contrast(a, b RGB) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	return (math.Max(la, lb) + 0.05) / (math.Min(la, lb) + 0.05)
+}
+
+// Chrome text colours used by the launchers on top of Accent and AccentDim.
+// They live here so the contrast audit checks exactly what gets emitted.
+
+// TitleFG is the text colour for active title bars, status bars and tabs.
+func (s *Scheme) TitleFG() string {
+	if s.IsLight() {
+		return "#1f1f1f"
+	}
+	return "#ffffff"
+}
+
+// TitleFGInactive is the text colour for inactive title bars.
+func (s *Scheme) TitleFGInactive() string {
+	if s.IsLight() {
+		return "#4d4d4d"
+	}
+	return "#cccccc"
+}
+
+// SelectionFG is the terminal selection text colour (no # prefix).
+func (s *Scheme) SelectionFG() string {
+	if s.IsLight() {
+		return "1f1f1f"
+	}
+	return "ffffff"
+}
+
+// Pair is one foreground/background combination emitted by a launcher.
+type Pair struct {
+	// Where describes the setting, e.g. "Cursor titleBar.active".
+	Where  string
+	FG, BG string
+	// NonText marks pairs that aren't text, like a cursor block, which only
+	// need to reach LevelAALarge.
+	NonText bool
+}
+
+// Pairs returns every foreground/background combination the launchers
+// produce for the scheme.
+func (s *Scheme) Pairs() []Pair {
+	return []Pair{
+		{Where: "Ghostty text", FG: s.GhosttyFG, BG: s.GhosttyBG},
+		{Where: "Ghostty selection", FG: s.SelectionFG(), BG: s.SelectionBG},
+		{Where: "Ghostty cursor", FG: s.CursorColor, BG: s.GhosttyBG, NonText: true},
+		{Where: "Cursor titleBar.active", FG: s.TitleFG(), BG: s.Accent},
+		{Where: "Cursor titleBar.inactive", FG: s.TitleFGInactive(), BG: s.AccentDim},
+		{Where: "Cursor activityBar/statusBar", FG: s.TitleFG(), BG: s.AccentDim},
+		{Where: "Firefox toolbar", FG: s.GhosttyFG, BG: s.Accent},
+		{Where: "Firefox nav-bar", FG: s.GhosttyFG, BG: s.AccentDim},
+		{Where: "Firefox selected tab", FG: s.TitleFG(), BG: s.Accent},
+		{Where: "Firefox tab strip", FG: s.GhosttyFG, BG: s.GhosttyBG},
+	}
+}
+
+// Issue is a pair whose contrast falls short of the required level.
+type Issue struct {
+	Scheme string
+	Pair   Pair
+	Ratio  float64
+	Min    Level
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s %s on %s is %.2f:1, below %s (%.1f:1)",
+		i.Scheme, i.Pair.Where, hashed(i.Pair.FG), hashed(i.Pair.BG), i.Ratio, i.Min, float64(i.Min))
+}
+
+// Audit returns the pairs in s and its light variant whose contrast is
+// below min. Non-text pairs are held to at most LevelAALarge.
+func Audit(s *Scheme, min Level) []Issue {
+	issues := auditVariant(s, min)
+	if !s.IsLight() {
+		issues = append(issues, auditVariant(s.Variant(Light), min)...)
+	}
+	return issues
+}
+
+func auditVariant(s *Scheme, min Level) []Issue {
+	name := s.Name
+	if s.IsLight() {
+		name += " (light)"
+	}
+	var issues []Issue
+	for _, p := range s.Pairs() {
+		need := min
+		if p.NonText {
+			need = Level(math.Min(float64(min), float64(LevelAALarge)))
+		}
+		r := Contrast(MustParseHex(p.FG), MustParseHex(p.BG))
+		if r < float64(need) {
+			issues = append(issues, Issue{Scheme: name, Pair: p, Ratio: r, Min: need})
+		}
+	}
+	return issues
+}
+
+// hashed adds a "#" prefix to a hex colour if it's missing.
+func hashed(hex string) string {
+	if strings.HasPrefix(hex, "#") {
+		return hex
+	}
+	return "#" + hex
+}
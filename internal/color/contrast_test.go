@@ -0,0 +1,107 @@
+package color
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestContrast(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"000000", "ffffff", 21},
+		{"ffffff", "000000", 21},
+		{"777777", "ffffff", 4.48},
+		{"ffffff", "ffffff", 1},
+		{"ff0000", "ffffff", 4},
+	}
+	for _, tt := range tests {
+		if got := Contrast(MustParseHex(tt.a), MustParseHex(tt.b)); math.Abs(got-tt.want) > 0.005 {
+			t.Errorf("Contrast(%s, %s) = %.3f, want %.2f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Level
+	}{
+		{"AA", LevelAA},
+		{"aa", LevelAA},
+		{"AAA", LevelAAA},
+		{"AA-large", LevelAALarge},
+		{" large ", LevelAALarge},
+		{"5.5", 5.5},
+		{"1", 1},
+		{"21", 21},
+	}
+	for _, tt := range tests {
+		if got, err := ParseLevel(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "A", "0.5", "22", "high"} {
+		if _, err := ParseLevel(in); err == nil {
+			t.Errorf("ParseLevel(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestAuditBuiltins(t *testing.T) {
+	for i := range Palettes {
+		for _, issue := range Audit(&Palettes[i], LevelAA) {
+			t.Error(issue)
+		}
+	}
+}
+
+func TestAuditReportsPairs(t *testing.T) {
+	s := *ByName("blue")
+	s.Accent = "#ffffff"
+	issues := Audit(&s, LevelAA)
+
+	var where []string
+	for _, issue := range issues {
+		if issue.Scheme != "blue" {
+			t.Errorf("issue for %q, want only the dark variant: %v", issue.Scheme, issue)
+		}
+		if !strings.Contains(issue.String(), issue.Pair.Where) {
+			t.Errorf("%q does not name the pair", issue.String())
+		}
+		where = append(where, issue.Pair.Where)
+	}
+	want := []string{"Cursor titleBar.active", "Firefox toolbar", "Firefox selected tab"}
+	if !slices.Equal(where, want) {
+		t.Errorf("failing pairs = %q, want %q", where, want)
+	}
+}
+
+func TestAuditNonTextCap(t *testing.T) {
+	// The cursor only has to reach AA-large, however strict the level.
+	for _, tt := range []struct {
+		cursor string
+		fails  bool
+	}{
+		{"666666", false}, // 3.66:1
+		{"404040", true},  // 2.03:1
+	} {
+		s := *ByName("blue")
+		s.GhosttyBG, s.CursorColor = "000000", tt.cursor
+		var found bool
+		for _, issue := range Audit(&s, LevelAAA) {
+			if issue.Pair.NonText {
+				found = true
+				if issue.Min != LevelAALarge {
+					t.Errorf("cursor held to %v, want AA-large", issue.Min)
+				}
+			}
+		}
+		if found != tt.fails {
+			t.Errorf("cursor %s on black: reported %v, want %v", tt.cursor, found, tt.fails)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package color
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Deficiency is a type of colour-vision deficiency to design the palette
+// around. The zero value means normal colour vision.
+type Deficiency string
+
+const (
+	NormalVision Deficiency = ""
+	// Protanopia is missing red (long-wavelength) cones.
+	Protanopia Deficiency = "protanopia"
+	// Deuteranopia is missing green (medium-wavelength) cones, the most
+	// common form of red/green colour blindness.
+	Deuteranopia Deficiency = "deuteranopia"
+	// Tritanopia is missing blue (short-wavelength) cones.
+	Tritanopia Deficiency = "tritanopia"
+)
+
+// Deficiencies lists the deficiencies that can be simulated.
+func Deficiencies() []Deficiency {
+	return []Deficiency{Protanopia, Deuteranopia, Tritanopia}
+}
+
+// ParseDeficiency parses a deficiency name (case-insensitive). "none" and
+// the empty string mean normal colour vision.
+func ParseDeficiency(s string) (Deficiency, error) {
+	switch d := Deficiency(strings.ToLower(strings.TrimSpace(s))); d {
+	case "none", NormalVision:
+		return NormalVision, nil
+	case Protanopia, Deuteranopia, Tritanopia:
+		return d, nil
+	}
+	return "", fmt.Errorf("invalid colour-vision deficiency %q (want protanopia, deuteranopia, tritanopia or none)", s)
+}
+
+// cvdMatrices are the full-severity simulation matrices from Machado,
+// Oliveira and Fernandes (2009), applied to linear sRGB.
+var cvdMatrices = map[Deficiency][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// Simulate returns how c appears to someone with deficiency d.
+func (c RGB) Simulate(d Deficiency) RGB {
+	m, ok := cvdMatrices[d]
+	if !ok {
+		return c
+	}
+	lin := [3]float64{toLinear(c.R), toLinear(c.G), toLinear(c.B)}
+	var out [3]float64
+	for i, row := range m {
+		v := row[0]*lin[0] + row[1]*lin[1] + row[2]*lin[2]
+		out[i] = fromLinear(max(0, min(1, v)))
+	}
+	return RGB{out[0], out[1], out[2]}
+}
+
+// MinDistinct is the smallest Distance at which two schemes still tell
+// projects apart at a glance. The closest built-in pair with normal colour
+// vision, blue and purple, is about 15 apart.
+const MinDistinct = 14.0
+
+// DistanceFor is Distance as seen by someone with deficiency d.
+func DistanceFor(a, b *Scheme, d Deficiency) float64 {
+	return DeltaE2000(MustParseHex(a.Base).Simulate(d), MustParseHex(b.Base).Simulate(d))
+}
+
+// Collision is a pair of schemes that become hard to tell apart under a
+// colour-vision deficiency.
+type Collision struct {
+	A, B *Scheme
+	// Distance is the difference under the deficiency; Normal is the
+	// difference with normal colour vision, for comparison.
+	Distance, Normal float64
+}
+
+func (c Collision) String() string {
+	return fmt.Sprintf("%s (%s) and %s (%s): ΔE %.1f, normally %.1f", c.A.Name, c.A.Base, c.B.Name, c.B.Base, c.Distance, c.Normal)
+}
+
+// Collisions returns every pair of schemes whose DistanceFor d is below min,
+// closest first.
+func Collisions(schemes []*Scheme, d Deficiency, min float64) []Collision {
+	var out []Collision
+	for i, a := range schemes {
+		for _, b := range schemes[i+1:] {
+			if dist := DistanceFor(a, b, d); dist < min {
+				out = append(out, Collision{A: a, B: b, Distance: dist, Normal: Distance(a, b)})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Distance < out[j].Distance })
+	return out
+}
@@ -0,0 +1,118 @@
+package color
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSimulateKeepsGreys(t *testing.T) {
+	// Each Machado matrix row sums to 1, so neutral colours are unchanged.
+	for _, d := range Deficiencies() {
+		for _, hex := range []string{"000000", "333333", "808080", "cccccc", "ffffff"} {
+			c := MustParseHex(hex)
+			if got := c.Simulate(d); got.Hex() != c.Hex() {
+				t.Errorf("%s: %s became %s", d, hex, got.Hex())
+			}
+		}
+	}
+}
+
+func TestSimulateKnownColours(t *testing.T) {
+	tests := []struct {
+		d          Deficiency
+		red, green string
+	}{
+		{NormalVision, "ff0000", "00ff00"},
+		{Protanopia, "6d5f00", "ffe500"},
+		{Deuteranopia, "a39000", "efd63a"},
+		{Tritanopia, "ff000f", "00f7d9"},
+	}
+	for _, tt := range tests {
+		if got := MustParseHex("ff0000").Simulate(tt.d).Hex(); got != tt.red {
+			t.Errorf("%q: red became %s, want %s", tt.d, got, tt.red)
+		}
+		if got := MustParseHex("00ff00").Simulate(tt.d).Hex(); got != tt.green {
+			t.Errorf("%q: green became %s, want %s", tt.d, got, tt.green)
+		}
+	}
+}
+
+func TestRedGreenCollapse(t *testing.T) {
+	red, green := MustParseHex(ByName("red").Base), MustParseHex(ByName("green").Base)
+	hueGap := func(d Deficiency) float64 {
+		a, b := red.Simulate(d).OKLCH().H, green.Simulate(d).OKLCH().H
+		gap := math.Abs(a - b)
+		return math.Min(gap, 360-gap)
+	}
+	normal := DeltaE2000(red, green)
+	for _, d := range []Deficiency{Protanopia, Deuteranopia} {
+		// Both turn a shade of yellow, told apart only by lightness.
+		if gap := hueGap(d); gap > 15 {
+			t.Errorf("%s: red and green hues %.0f° apart, want them to converge", d, gap)
+		}
+		if dist := DeltaE2000(red.Simulate(d), green.Simulate(d)); dist > normal/2 {
+			t.Errorf("%s: red and green ΔE %.1f, normally %.1f; want under half", d, dist, normal)
+		}
+	}
+	if gap := hueGap(Tritanopia); gap < 90 {
+		t.Errorf("tritanopia: red and green hues only %.0f° apart", gap)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	var builtins []*Scheme
+	for i := range Palettes {
+		builtins = append(builtins, &Palettes[i])
+	}
+	pairs := func(d Deficiency) [][2]string {
+		var out [][2]string
+		prev := 0.0
+		for _, c := range Collisions(builtins, d, MinDistinct) {
+			if c.Distance < prev {
+				t.Errorf("%s: %v listed after a wider pair", d, c)
+			}
+			if c.Distance >= MinDistinct || c.Normal <= c.Distance {
+				t.Errorf("%s: %v should not collide", d, c)
+			}
+			prev = c.Distance
+			out = append(out, [2]string{c.A.Name, c.B.Name})
+		}
+		return out
+	}
+
+	if got := pairs(NormalVision); len(got) != 0 {
+		t.Errorf("built-ins collide with normal colour vision: %v", got)
+	}
+	want := [][2]string{
+		{"green", "orange"},
+		{"green", "gold"},
+		{"blue", "purple"},
+		{"orange", "gold"},
+		{"red", "orange"},
+	}
+	if got := pairs(Deuteranopia); !slices.Equal(got, want) {
+		t.Errorf("deuteranopia collisions = %v, want %v", got, want)
+	}
+}
+
+func TestParseDeficiency(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Deficiency
+	}{
+		{"", NormalVision},
+		{"none", NormalVision},
+		{"Deuteranopia", Deuteranopia},
+		{" protanopia ", Protanopia},
+		{"TRITANOPIA", Tritanopia},
+	}
+	for _, tt := range tests {
+		if got, err := ParseDeficiency(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseDeficiency(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseDeficiency("colourblind"); err == nil {
+		t.Error("ParseDeficiency accepted an unknown name")
+	}
+}
@@ -0,0 +1,113 @@
+package color
+
+import (
+	"strings"
+)
+
+// customPrefix names schemes generated on the fly from a hex colour, e.g.
+// "custom-ff8800". Such names resolve through ByName without being listed in
+// Palettes, so assignments that use them survive a restart.
+const customPrefix = "custom-"
+
+// role is the lightness and chroma of one Scheme field in OKLCH.
+type role struct {
+	L, C float64
+}
+
+// profile holds a role for every colour field of a Scheme.
+type profile struct {
+	GhosttyBG, GhosttyFG, Cursor, SelectionBG, Accent, AccentDim, Base role
+}
+
+// darkProfile is the average lightness and chroma measured across the
+// built-in palettes, so generated schemes sit at the same brightness and tint
+// strength as the hand-made ones.
+var darkProfile = profile{
+	GhosttyBG:   role{0.245, 0.050},
+	GhosttyFG:   role{0.900, 0.043},
+	Cursor:      role{0.780, 0.190},
+	SelectionBG: role{0.370, 0.080},
+	Accent:      role{0.400, 0.110},
+	AccentDim:   role{0.310, 0.085},
+	Base:        role{0.640, 0.190},
+}
+
+// lightProfile mirrors darkProfile for light themes: a near-white tinted
+// background with dark text, and pastel chrome that takes dark title text.
+var lightProfile = profile{
+	GhosttyBG:   role{0.970, 0.018},
+	GhosttyFG:   role{0.300, 0.050},
+	Cursor:      role{0.550, 0.170},
+	SelectionBG: role{0.870, 0.060},
+	Accent:      role{0.820, 0.090},
+	AccentDim:   role{0.900, 0.050},
+	Base:        role{0.640, 0.190},
+}
+
+// Generate derives a complete Scheme from a single base colour. The base is
+// kept as-is for Base; every other field takes the base's hue with the
+// lightness and chroma profile of the built-in palettes. Chroma is scaled
+// down for muted bases, so a grey base produces a grey scheme.
+func Generate(name, base string) (*Scheme, error) {
+	c, err := ParseHex(base)
+	if err != nil {
+		return nil, err
+	}
+	o := c.OKLCH()
+	s := fromHue(name, o.H, chromaScale(o), darkProfile)
+	s.Base = "#" + c.Hex()
+	return s, nil
+}
+
+// chromaScale returns how saturated a base colour is relative to the
+// built-in palettes, capped at 1.
+func chromaScale(base OKLCH) float64 {
+	return min(1, base.C/darkProfile.Base.C)
+}
+
+// fromHue builds a scheme for a hue (in OKLCH degrees) from a profile, with
+// chroma multiplied by scale.
+func fromHue(name string, hue, scale float64, p profile) *Scheme {
+	at := func(r role) string {
+		return OKLCH{L: r.L, C: r.C * scale, H: hue}.RGB().Hex()
+	}
+	return &Scheme{
+		Name:        name,
+		GhosttyBG:   at(p.GhosttyBG),
+		GhosttyFG:   at(p.GhosttyFG),
+		CursorColor: at(p.Cursor),
+		SelectionBG: at(p.SelectionBG),
+		Accent:      "#" + at(p.Accent),
+		AccentDim:   "#" + at(p.AccentDim),
+		Base:        "#" + at(p.Base),
+	}
+}
+
+// generatedByName resolves names that describe a generated scheme: a hex
+// colour such as "#ff8800", the "custom-ff8800" name it is stored under, a
+// palette-extension hue such as "hue-217", or a family shade such as
+// "shade-250-3". It returns nil for any other name.
+func generatedByName(name string) *Scheme {
+	var hex string
+	switch {
+	case strings.HasPrefix(name, huePrefix):
+		return hueByName(name)
+	case strings.HasPrefix(name, shadePrefix):
+		return shadeByName(name)
+	case strings.HasPrefix(name, "#"):
+		hex = name
+	case strings.HasPrefix(name, customPrefix):
+		hex = strings.TrimPrefix(name, customPrefix)
+	default:
+		return nil
+	}
+	h, err := normalizeHex(hex)
+	if err != nil {
+		return nil
+	}
+	s, err := Generate(customPrefix+h, h)
+	if err != nil {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+// fields returns a scheme's colour fields with the profile role each is
+// generated from.
+func fields(s *Scheme, p profile) []struct {
+	name string
+	hex  string
+	role role
+} {
+	return []struct {
+		name string
+		hex  string
+		role role
+	}{
+		{"ghostty_bg", s.GhosttyBG, p.GhosttyBG},
+		{"ghostty_fg", s.GhosttyFG, p.GhosttyFG},
+		{"cursor_color", s.CursorColor, p.Cursor},
+		{"selection_bg", s.SelectionBG, p.SelectionBG},
+		{"accent", s.Accent, p.Accent},
+		{"accent_dim", s.AccentDim, p.AccentDim},
+	}
+}
+
+func TestGenerateProfile(t *testing.T) {
+	for _, base := range []string{"#ff8800", "#3366cc", "#33cc33", "#cc33cc", "#20b2aa", "#808080"} {
+		s, err := Generate("x", base)
+		if err != nil {
+			t.Fatalf("Generate(%s): %v", base, err)
+		}
+		if s.Base != base {
+			t.Errorf("%s: Base = %s, want the base unchanged", base, s.Base)
+		}
+		scale := chromaScale(MustParseHex(base).OKLCH())
+		for _, f := range fields(s, darkProfile) {
+			o := MustParseHex(f.hex).OKLCH()
+			// Gamut mapping only takes chroma away; lightness is kept, up
+			// to rounding to 8 bits.
+			if math.Abs(o.L-f.role.L) > 0.01 {
+				t.Errorf("%s %s: L %.3f, want %.3f", base, f.name, o.L, f.role.L)
+			}
+			if o.C > f.role.C*scale+0.01 {
+				t.Errorf("%s %s: C %.3f, more than %.3f", base, f.name, o.C, f.role.C*scale)
+			}
+		}
+	}
+}
+
+func TestGenerateGreyBase(t *testing.T) {
+	s, err := Generate("x", "#808080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fields(s, darkProfile) {
+		if c := MustParseHex(f.hex).OKLCH().C; c > 0.005 {
+			t.Errorf("%s: chroma %.4f from a grey base", f.name, c)
+		}
+	}
+}
+
+// TestGenerateMatchesBuiltIns checks that, averaged over the built-in
+// palettes, schemes generated from their base colours sit at the same
+// lightness and chroma as the hand-made ones.
+func TestGenerateMatchesBuiltIns(t *testing.T) {
+	var builtIn []*Scheme
+	for _, name := range []string{"red", "blue", "green", "purple", "orange", "teal", "pink", "gold"} {
+		if s := ByName(name); s != nil {
+			builtIn = append(builtIn, s)
+		}
+	}
+	if len(builtIn) == 0 {
+		t.Fatal("no built-in schemes")
+	}
+	sumL := make([]float64, 6)
+	sumC := make([]float64, 6)
+	for _, s := range builtIn {
+		g, err := Generate("x", s.Base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, got := fields(s, darkProfile), fields(g, darkProfile)
+		for i := range want {
+			w, o := MustParseHex(want[i].hex).OKLCH(), MustParseHex(got[i].hex).OKLCH()
+			sumL[i] += o.L - w.L
+			sumC[i] += o.C - w.C
+		}
+	}
+	for i, f := range fields(builtIn[0], darkProfile) {
+		n := float64(len(builtIn))
+		if dl := sumL[i] / n; math.Abs(dl) > 0.03 {
+			t.Errorf("%s: lightness off the built-ins by %+.3f on average", f.name, dl)
+		}
+		// Gamut mapping costs the brightest fields some chroma, so this
+		// is looser.
+		if dc := sumC[i] / n; math.Abs(dc) > 0.05 {
+			t.Errorf("%s: chroma off the built-ins by %+.3f on average", f.name, dc)
+		}
+	}
+}
+
+func TestCustomScheme(t *testing.T) {
+	want := Scheme{
+		Name:        "custom-ff8800",
+		GhosttyBG:   "311a07",
+		GhosttyFG:   "f4d8c4",
+		CursorColor: "ff9c4c",
+		SelectionBG: "5e3411",
+		Accent:      "#6e3700",
+		AccentDim:   "#4c2400",
+		Base:        "#ff8800",
+	}
+	// --color '#ff8800' is looked up as given, and the assignment is then
+	// stored, and later resolved, under the custom- name.
+	for _, name := range []string{"#ff8800", "#FF8800", "#f80", "custom-ff8800"} {
+		s := ByName(name)
+		if s == nil {
+			t.Errorf("ByName(%q) = nil", name)
+			continue
+		}
+		if *s != want {
+			t.Errorf("ByName(%q) = %+v, want %+v", name, *s, want)
+		}
+	}
+	if s := ByName("custom-nothex"); s != nil {
+		t.Errorf("ByName(custom-nothex) = %+v, want nil", *s)
+	}
+}
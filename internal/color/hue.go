@@ -0,0 +1,54 @@
+package color
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// huePrefix names schemes generated for a hue when the palette is extended,
+// e.g. "hue-217". Like "custom-" names they resolve through ByName, so the
+// name alone is enough to rebuild the scheme.
+const huePrefix = "hue-"
+
+// HueScheme returns the scheme generated for a hue in OKLCH degrees, using
+// the same lightness and chroma as the built-in palettes. It is named
+// "hue-<degrees>".
+func HueScheme(hue int) *Scheme {
+	hue = (hue%360 + 360) % 360
+	return fromHue(huePrefix+strconv.Itoa(hue), float64(hue), 1, darkProfile)
+}
+
+// NextHue returns the whole-degree hue in the middle of the widest gap
+// between the base colours of schemes, so hues added one at a time stay
+// evenly spread around the colour wheel.
+func NextHue(schemes []*Scheme) int {
+	if len(schemes) == 0 {
+		return 0
+	}
+	hues := make([]float64, len(schemes))
+	for i, s := range schemes {
+		hues[i] = MustParseHex(s.Base).OKLCH().H
+	}
+	sort.Float64s(hues)
+
+	start, width := hues[len(hues)-1], hues[0]+360-hues[len(hues)-1]
+	for i := 1; i < len(hues); i++ {
+		if gap := hues[i] - hues[i-1]; gap > width {
+			start, width = hues[i-1], gap
+		}
+	}
+	return int(start+width/2+0.5) % 360
+}
+
+// hueByName resolves "hue-<degrees>" names, or returns nil.
+func hueByName(name string) *Scheme {
+	deg, err := strconv.Atoi(strings.TrimPrefix(name, huePrefix))
+	if err != nil || deg < 0 || deg >= 360 {
+		return nil
+	}
+	if s := HueScheme(deg); s.Name == name {
+		return s
+	}
+	return nil
+}
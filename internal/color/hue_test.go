@@ -0,0 +1,128 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+// hueGap returns the angle between two hues in degrees, from 0 to 180.
+func hueGap(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+// extend adds n schemes to the built-ins the way extend_palette does, one
+// NextHue at a time, and returns the names it generated.
+func extend(n int) []string {
+	var schemes []*Scheme
+	for i := range Palettes {
+		schemes = append(schemes, &Palettes[i])
+	}
+	var names []string
+	for range n {
+		s := HueScheme(NextHue(schemes))
+		schemes = append(schemes, s)
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestNextHue(t *testing.T) {
+	if got := NextHue(nil); got != 0 {
+		t.Errorf("NextHue(nil) = %d, want 0", got)
+	}
+	// One scheme: the widest gap is the rest of the wheel.
+	one := HueScheme(40)
+	want := int(math.Round(MustParseHex(one.Base).OKLCH().H+180)) % 360
+	if got := NextHue([]*Scheme{one}); got != want {
+		t.Errorf("NextHue(hue-40) = %d, want %d", got, want)
+	}
+}
+
+func TestHueSchemeNames(t *testing.T) {
+	for _, tt := range []struct {
+		hue  int
+		name string
+	}{
+		{0, "hue-0"},
+		{217, "hue-217"},
+		{360, "hue-0"},
+		{-10, "hue-350"},
+	} {
+		if got := HueScheme(tt.hue).Name; got != tt.name {
+			t.Errorf("HueScheme(%d) named %q, want %q", tt.hue, got, tt.name)
+		}
+	}
+	for _, name := range []string{"hue-0", "hue-217", "hue-359"} {
+		s := ByName(name)
+		if s == nil || s.Name != name {
+			t.Errorf("ByName(%q) = %v", name, s)
+		}
+	}
+	for _, name := range []string{"hue-360", "hue--1", "hue-07", "hue-x", "hue-"} {
+		if s := ByName(name); s != nil {
+			t.Errorf("ByName(%q) = %s, want nil", name, s.Name)
+		}
+	}
+}
+
+func TestHueSchemeBaseHue(t *testing.T) {
+	// NextHue measures gaps from each scheme's base colour, so a
+	// generated base has to land on the hue it is named after.
+	for hue := 0; hue < 360; hue += 15 {
+		if got := MustParseHex(HueScheme(hue).Base).OKLCH().H; hueGap(got, float64(hue)) > 2 {
+			t.Errorf("hue-%d has base hue %.1f", hue, got)
+		}
+	}
+}
+
+func TestExtendDeterministic(t *testing.T) {
+	first := extend(12)
+	if again := extend(12); !slices.Equal(first, again) {
+		t.Errorf("extending twice gave %q, then %q", first, again)
+	}
+	// Extending further only appends: names already handed out, and so
+	// persisted, stay as they were.
+	if more := extend(16); !slices.Equal(more[:12], first) {
+		t.Errorf("extending to 16 renamed the first 12: %q, was %q", more[:12], first)
+	}
+	seen := make(map[string]bool)
+	for _, name := range first {
+		if seen[name] {
+			t.Errorf("%s generated twice", name)
+		}
+		seen[name] = true
+		if ByName(name) == nil {
+			t.Errorf("%s does not resolve", name)
+		}
+	}
+}
+
+func TestExtendSpread(t *testing.T) {
+	hues := make([]float64, 0, len(Palettes))
+	for _, s := range Palettes {
+		hues = append(hues, MustParseHex(s.Base).OKLCH().H)
+	}
+	for i, name := range extend(16) {
+		var h float64
+		fmt.Sscanf(name, "hue-%g", &h)
+
+		// Each new hue splits the widest gap, so it sits at least half
+		// of that gap from every hue before it.
+		slices.Sort(hues)
+		widest := hues[0] + 360 - hues[len(hues)-1]
+		for j := 1; j < len(hues); j++ {
+			widest = math.Max(widest, hues[j]-hues[j-1])
+		}
+		nearest := 360.0
+		for _, o := range hues {
+			nearest = math.Min(nearest, hueGap(h, o))
+		}
+		if nearest < widest/2-1 {
+			t.Errorf("scheme %d (%s) is %.1f° from its neighbour; the widest gap was %.1f°", i+1, name, nearest, widest)
+		}
+		hues = append(hues, MustParseHex(ByName(name).Base).OKLCH().H)
+	}
+}
@@ -0,0 +1,275 @@
+package color
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// PaletteFile is a user-defined set of schemes loaded from a palette file.
+//
+// The file is TOML with one [[scheme]] table per scheme, using the same field
+// names as the JSON tags on Scheme:
+//
+//	# Drop the built-in schemes entirely (default: merge with them).
+//	replace = false
+//	# Minimum WCAG contrast for every pair the launchers emit (default: AA).
+//	min_contrast = "AA"
+//
+//	[[scheme]]
+//	name         = "navy"
+//	ghostty_bg   = "0d1526"
+//	ghostty_fg   = "d0d8f0"
+//	cursor_color = "6688ff"
+//	selection_bg = "1f2d4d"
+//	accent       = "#1a2d5a"
+//	accent_dim   = "#111e3d"
+//	base         = "#3355aa"
+//
+// Only name and base are required: any other field that is left out is
+// derived from base the same way Generate does it. An optional [scheme.light]
+// table hand-tunes the light variant with the same colour keys; anything it
+// leaves out is generated (see Scheme.Variant).
+type PaletteFile struct {
+	// Replace drops the built-in schemes instead of merging with them.
+	Replace bool
+	Schemes []Scheme
+}
+
+// colourKeys are the colour fields accepted in [[scheme]] and
+// [scheme.light] tables.
+var colourKeys = []string{
+	"ghostty_bg", "ghostty_fg", "cursor_color", "selection_bg",
+	"accent", "accent_dim", "base",
+}
+
+// ParsePalette parses and validates a palette file. Errors carry the line
+// number of the offending value.
+func ParsePalette(data []byte) (*PaletteFile, error) {
+	root, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := root.CheckKeys("replace", "min_contrast", "scheme"); err != nil {
+		return nil, err
+	}
+
+	level := LevelAA
+	if v := root.Get("min_contrast"); v != nil {
+		raw, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		if level, err = ParseLevel(raw); err != nil {
+			return nil, toml.Errorf(v.Line, "min_contrast: %v", err)
+		}
+	}
+
+	f := &PaletteFile{}
+	if v := root.Get("replace"); v != nil {
+		if f.Replace, err = v.AsBool(); err != nil {
+			return nil, err
+		}
+	}
+
+	tables, err := root.Tables("scheme")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]int)
+	for _, t := range tables {
+		// Report duplicates before field errors, since a copy-pasted
+		// table is the most likely cause of both.
+		if v := t.Get("name"); v != nil {
+			if name, err := v.AsString(); err == nil {
+				if line, ok := seen[name]; ok {
+					return nil, toml.Errorf(v.Line, "duplicate scheme name %q (first defined on line %d)", name, line)
+				}
+				seen[name] = v.Line
+			}
+		}
+		s, err := parseScheme(t)
+		if err != nil {
+			return nil, err
+		}
+		if issues := Audit(s, level); len(issues) > 0 {
+			return nil, toml.Errorf(t.Line, "%s (set min_contrast to relax this)", issues[0])
+		}
+		f.Schemes = append(f.Schemes, *s)
+	}
+	if f.Replace && len(f.Schemes) == 0 {
+		return nil, toml.Errorf(root.Get("replace").Line, "replace = true but no [[scheme]] entries are defined")
+	}
+	return f, nil
+}
+
+func parseScheme(t *toml.Table) (*Scheme, error) {
+	if err := t.CheckKeys(append([]string{"name", "light"}, colourKeys...)...); err != nil {
+		return nil, err
+	}
+	nameVal := t.Get("name")
+	if nameVal == nil {
+		return nil, toml.Errorf(t.Line, "scheme is missing a name")
+	}
+	name, err := nameVal.AsString()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateName(name); err != nil {
+		return nil, toml.Errorf(nameVal.Line, "%v", err)
+	}
+
+	baseVal := t.Get("base")
+	if baseVal == nil {
+		return nil, toml.Errorf(t.Line, "scheme %q is missing base", name)
+	}
+	rawBase, err := baseVal.AsString()
+	if err != nil {
+		return nil, err
+	}
+	derived, err := Generate(name, rawBase)
+	if err != nil {
+		return nil, toml.Errorf(baseVal.Line, "scheme %q: base: %v", name, err)
+	}
+
+	s := derived
+	if err := parseColours(t, name, s); err != nil {
+		return nil, err
+	}
+
+	if v := t.Get("light"); v != nil {
+		lt, err := v.AsTable()
+		if err != nil {
+			return nil, err
+		}
+		if err := lt.CheckKeys(colourKeys...); err != nil {
+			return nil, err
+		}
+		light := lightFrom(s)
+		if err := parseColours(lt, name+" (light)", light); err != nil {
+			return nil, err
+		}
+		s.LightVariant = light
+	}
+	return s, nil
+}
+
+// parseColours overwrites the colour fields of s with any that are set in t.
+func parseColours(t *toml.Table, label string, s *Scheme) error {
+	fields := []struct {
+		key  string
+		dst  *string
+		hash bool
+	}{
+		{"ghostty_bg", &s.GhosttyBG, false},
+		{"ghostty_fg", &s.GhosttyFG, false},
+		{"cursor_color", &s.CursorColor, false},
+		{"selection_bg", &s.SelectionBG, false},
+		{"accent", &s.Accent, true},
+		{"accent_dim", &s.AccentDim, true},
+		{"base", &s.Base, true},
+	}
+	for _, fld := range fields {
+		v := t.Get(fld.key)
+		if v == nil {
+			continue
+		}
+		raw, err := v.AsString()
+		if err != nil {
+			return err
+		}
+		hex, err := normalizeHex(raw)
+		if err != nil {
+			return toml.Errorf(v.Line, "scheme %q: %s: %v", label, fld.key, err)
+		}
+		// Keep the same prefix convention as the built-in palettes.
+		if fld.hash {
+			hex = "#" + hex
+		}
+		*fld.dst = hex
+	}
+	return nil
+}
+
+// validateName checks that a scheme name is safe to use in theme file and
+// Firefox profile names.
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("scheme name must not be empty")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("invalid scheme name %q: use lowercase letters, digits, '-' and '_'", name)
+		}
+	}
+	return nil
+}
+
+// normalizeHex accepts "#rgb", "#rrggbb" or the same without "#" and returns
+// the lowercase six-digit form without the "#".
+func normalizeHex(s string) (string, error) {
+	h := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "#"))
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	if len(h) != 6 {
+		return "", fmt.Errorf("invalid hex colour %q (want #rrggbb)", s)
+	}
+	for _, c := range h {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return "", fmt.Errorf("invalid hex colour %q (want #rrggbb)", s)
+		}
+	}
+	return h, nil
+}
+
+// Merge installs the schemes from a palette file into Palettes. A scheme
+// whose name matches an existing one replaces it in place, so user files can
+// retune a built-in colour without changing its position in the assignment
+// order; new names are appended. If the file sets replace, the built-ins are
+// dropped entirely.
+func Merge(f *PaletteFile) {
+	if f.Replace {
+		Palettes = append([]Scheme(nil), f.Schemes...)
+		return
+	}
+	for _, s := range f.Schemes {
+		// Not ByName: a generated name such as "hue-217" resolves there
+		// without being in Palettes.
+		if i := slices.IndexFunc(Palettes, func(p Scheme) bool { return p.Name == s.Name }); i >= 0 {
+			Palettes[i] = s
+			continue
+		}
+		Palettes = append(Palettes, s)
+	}
+}
+
+// FormatPaletteEntry returns s as a [[scheme]] table for a palette file,
+// including a [scheme.light] table if it has a hand-tuned light variant.
+func FormatPaletteEntry(s *Scheme) string {
+	var b strings.Builder
+	b.WriteString("[[scheme]]\n")
+	fmt.Fprintf(&b, "%-12s = %s\n", "name", toml.Quote(s.Name))
+	writeColours(&b, s)
+	if s.LightVariant != nil {
+		b.WriteString("\n[scheme.light]\n")
+		writeColours(&b, s.LightVariant)
+	}
+	return b.String()
+}
+
+func writeColours(b *strings.Builder, s *Scheme) {
+	for _, f := range []struct{ key, value string }{
+		{"ghostty_bg", s.GhosttyBG},
+		{"ghostty_fg", s.GhosttyFG},
+		{"cursor_color", s.CursorColor},
+		{"selection_bg", s.SelectionBG},
+		{"accent", s.Accent},
+		{"accent_dim", s.AccentDim},
+		{"base", s.Base},
+	} {
+		fmt.Fprintf(b, "%-12s = %s\n", f.key, toml.Quote(f.value))
+	}
+}
@@ -20,6 +20,15 @@ type Scheme struct {
 	AccentDim string `json:"accent_dim"`
 	// Base is the pure hue at full saturation, used for JankyBorders (with # prefix).
 	Base string `json:"base"`
+
+	// Appearance is Light for light variants; empty or Dark otherwise.
+	Appearance Appearance `json:"appearance,omitempty"`
+	// LightVariant is an optional hand-tuned light variant. When nil,
+	// Variant generates one from Base.
+	LightVariant *Scheme `json:"light,omitempty"`
+
+	// dark is the scheme a light variant was derived from.
+	dark *Scheme
 }
 
 // Palettes defines the built-in set of workspace color schemes.
@@ -110,13 +119,15 @@ var Palettes = []Scheme{
 }
 
 // ByName returns the scheme with the given name, or nil if not found.
+// Besides palette names it accepts a hex colour like "#ff8800", which returns
+// a scheme generated from that colour (see Generate).
 func ByName(name string) *Scheme {
 	for i := range Palettes {
 		if Palettes[i].Name == name {
 			return &Palettes[i]
 		}
 	}
-	return nil
+	return generatedByName(name)
 }
 
 // Names returns all available scheme names.
@@ -127,3 +138,9 @@ func Names() []string {
 	}
 	return names
 }
+
+// Distance returns how different two schemes look, as the CIEDE2000
+// difference between their base colours.
+func Distance(a, b *Scheme) float64 {
+	return DeltaE2000(MustParseHex(a.Base), MustParseHex(b.Base))
+}
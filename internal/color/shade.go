@@ -0,0 +1,79 @@
+package color
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// shadePrefix names the schemes handed to members of a colour family,
+// e.g. "shade-250-3" for the third shade of hue 250. Like "hue-" names they
+// resolve through ByName.
+const shadePrefix = "shade-"
+
+// shade is a lightness offset and chroma multiplier applied to the
+// built-in profile.
+type shade struct {
+	dL, c float64
+}
+
+// shades are the variants of one hue that family members are given, in
+// the order they are handed out. The first is the plain hue; after that
+// they alternate lighter and darker, more and less saturated, so members
+// added one after another look as different as the hue allows. Light text
+// sits on the chrome, so offsets lean darker to keep it readable.
+var shades = []shade{
+	{0, 1},
+	{-0.08, 0.5},
+	{0.04, 1.3},
+	{-0.13, 1.2},
+	{-0.03, 0.45},
+	{0.03, 0.7},
+	{-0.11, 0.75},
+	{-0.05, 1.45},
+}
+
+// ShadeCount is the number of distinct shades a hue has.
+func ShadeCount() int { return len(shades) }
+
+// shadeName returns the name of shade n (1-based) of a hue.
+func shadeName(hue, n int) string {
+	return fmt.Sprintf("%s%d-%d", shadePrefix, (hue%360+360)%360, n)
+}
+
+// ShadeScheme returns shade n (1-based, up to ShadeCount) of a hue in
+// OKLCH degrees. Chrome, cursor, selection and border colours take the
+// shade's full lightness offset; the terminal background only a quarter of
+// it, so text contrast stays close to the built-in palettes.
+func ShadeScheme(hue, n int) *Scheme {
+	sh := shades[(n-1)%len(shades)]
+	p := darkProfile
+	for _, r := range []*role{&p.Cursor, &p.SelectionBG, &p.Accent, &p.AccentDim, &p.Base} {
+		r.L += sh.dL
+	}
+	p.GhosttyBG.L += sh.dL / 4
+	hue = (hue%360 + 360) % 360
+	return fromHue(shadeName(hue, n), float64(hue), sh.c, p)
+}
+
+// shadeByName resolves "shade-<hue>-<n>" names, or returns nil.
+func shadeByName(name string) *Scheme {
+	hue, n, ok := strings.Cut(strings.TrimPrefix(name, shadePrefix), "-")
+	if !ok {
+		return nil
+	}
+	h, err1 := strconv.Atoi(hue)
+	i, err2 := strconv.Atoi(n)
+	if err1 != nil || err2 != nil || h < 0 || h >= 360 || i < 1 || i > len(shades) {
+		return nil
+	}
+	if s := ShadeScheme(h, i); s.Name == name {
+		return s
+	}
+	return nil
+}
+
+// IsShadeOf reports whether name is one of hue's shades.
+func IsShadeOf(name string, hue int) bool {
+	return strings.HasPrefix(name, fmt.Sprintf("%s%d-", shadePrefix, (hue%360+360)%360))
+}
@@ -0,0 +1,66 @@
+package color
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShadeNames(t *testing.T) {
+	for n := 1; n <= ShadeCount(); n++ {
+		s := ShadeScheme(250, n)
+		want := fmt.Sprintf("shade-250-%d", n)
+		if s.Name != want {
+			t.Errorf("shade %d named %q, want %q", n, s.Name, want)
+		}
+		if got := ByName(s.Name); got == nil || *got != *s {
+			t.Errorf("ByName(%q) does not rebuild the shade", s.Name)
+		}
+		if !IsShadeOf(s.Name, 250) || !IsShadeOf(s.Name, 610) {
+			t.Errorf("%s is not a shade of 250", s.Name)
+		}
+		if IsShadeOf(s.Name, 25) || IsShadeOf(s.Name, 251) {
+			t.Errorf("%s counted as a shade of another hue", s.Name)
+		}
+	}
+	if got := ShadeScheme(-10, 2).Name; got != "shade-350-2" {
+		t.Errorf("ShadeScheme(-10, 2) named %q, want shade-350-2", got)
+	}
+	for _, name := range []string{"shade-250-0", "shade-250-9", "shade-360-1", "shade-250", "shade-x-1", "shade-250-01"} {
+		if s := ByName(name); s != nil {
+			t.Errorf("ByName(%q) = %s, want nil", name, s.Name)
+		}
+	}
+}
+
+func TestShadesDistinct(t *testing.T) {
+	// Shades are handed out in order, so each should stand apart from the
+	// one before it, and none may repeat another.
+	for _, hue := range []int{25, 145, 250} {
+		bases := make(map[string]string)
+		for n := 1; n <= ShadeCount(); n++ {
+			s := ShadeScheme(hue, n)
+			if base := MustParseHex(s.Base).OKLCH().H; hueGap(base, float64(hue)) > 3 {
+				t.Errorf("%s has base hue %.1f", s.Name, base)
+			}
+			if prev, ok := bases[s.Base]; ok {
+				t.Errorf("%s has the same base as %s", s.Name, prev)
+			}
+			bases[s.Base] = s.Name
+			if n > 1 {
+				if d := Distance(ShadeScheme(hue, n-1), s); d < 5 {
+					t.Errorf("%s is only %.1f from the shade before it", s.Name, d)
+				}
+			}
+		}
+	}
+}
+
+func TestShadesPassAudit(t *testing.T) {
+	for hue := 0; hue < 360; hue += 30 {
+		for n := 1; n <= ShadeCount(); n++ {
+			for _, issue := range Audit(ShadeScheme(hue, n), LevelAA) {
+				t.Error(issue)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package color
+
+import (
+	"fmt"
+	"math"
+)
+
+// RGB is an sRGB colour with components in [0, 1].
+type RGB struct {
+	R, G, B float64
+}
+
+// ParseHex parses "#rrggbb", "rrggbb" or the three-digit shorthand.
+func ParseHex(s string) (RGB, error) {
+	h, err := normalizeHex(s)
+	if err != nil {
+		return RGB{}, err
+	}
+	var r, g, b uint8
+	fmt.Sscanf(h, "%02x%02x%02x", &r, &g, &b)
+	return RGB{float64(r) / 255, float64(g) / 255, float64(b) / 255}, nil
+}
+
+// MustParseHex is like ParseHex but panics on invalid input. It is intended
+// for the hex values in Scheme, which are validated when loaded.
+func MustParseHex(s string) RGB {
+	c, err := ParseHex(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Hex returns the colour as lowercase "rrggbb" without a "#" prefix.
+func (c RGB) Hex() string {
+	to8 := func(v float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	return fmt.Sprintf("%02x%02x%02x", to8(c.R), to8(c.G), to8(c.B))
+}
+
+// inGamut reports whether the colour is representable in sRGB, with a little
+// slack for floating-point error.
+func (c RGB) inGamut() bool {
+	const eps = 1e-4
+	return c.R >= -eps && c.R <= 1+eps && c.G >= -eps && c.G <= 1+eps && c.B >= -eps && c.B <= 1+eps
+}
+
+func toLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func fromLinear(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// OKLCH is a colour in the OKLCH space: perceptual lightness L in [0, 1],
+// chroma C (roughly [0, 0.37] for sRGB) and hue H in degrees.
+type OKLCH struct {
+	L, C, H float64
+}
+
+// OKLCH converts an sRGB colour to OKLCH.
+func (c RGB) OKLCH() OKLCH {
+	r, g, b := toLinear(c.R), toLinear(c.G), toLinear(c.B)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	L := 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	A := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	B := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+
+	h := math.Atan2(B, A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return OKLCH{L: L, C: math.Hypot(A, B), H: h}
+}
+
+// rgb converts to sRGB without clamping; the result may be out of gamut.
+func (o OKLCH) rgb() RGB {
+	hr := o.H * math.Pi / 180
+	A, B := o.C*math.Cos(hr), o.C*math.Sin(hr)
+
+	l := o.L + 0.3963377774*A + 0.2158037573*B
+	m := o.L - 0.1055613458*A - 0.0638541728*B
+	s := o.L - 0.0894841775*A - 1.2914855480*B
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return RGB{
+		R: fromLinear(+4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		G: fromLinear(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		B: fromLinear(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+	}
+}
+
+// RGB converts to sRGB, reducing chroma as needed to stay inside the sRGB
+// gamut so that hue and lightness are preserved.
+func (o OKLCH) RGB() RGB {
+	if c := o.rgb(); c.inGamut() {
+		return c
+	}
+	lo, hi := 0.0, o.C
+	for range 24 {
+		mid := (lo + hi) / 2
+		if (OKLCH{o.L, mid, o.H}).rgb().inGamut() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return OKLCH{o.L, lo, o.H}.rgb()
+}
+
+// lab is a colour in CIE L*a*b* (D65 white point).
+type lab struct {
+	L, A, B float64
+}
+
+func (c RGB) lab() lab {
+	r, g, b := toLinear(c.R), toLinear(c.G), toLinear(c.B)
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 216.0/24389 {
+			return math.Cbrt(t)
+		}
+		return (24389.0/27*t + 16) / 116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return lab{L: 116*fy - 16, A: 500 * (fx - fy), B: 200 * (fy - fz)}
+}
+
+// DeltaE2000 returns the CIEDE2000 perceptual difference between two
+// colours. Around 2 is barely noticeable; above 20 or so reads as clearly
+// different colours.
+func DeltaE2000(c1, c2 RGB) float64 {
+	p, q := c1.lab(), c2.lab()
+	rad := math.Pi / 180
+
+	cp := math.Hypot(p.A, p.B)
+	cq := math.Hypot(q.A, q.B)
+	cMean7 := math.Pow((cp+cq)/2, 7)
+	g := 0.5 * (1 - math.Sqrt(cMean7/(cMean7+math.Pow(25, 7))))
+
+	a1, a2 := (1+g)*p.A, (1+g)*q.A
+	c1p, c2p := math.Hypot(a1, p.B), math.Hypot(a2, q.B)
+	hue := func(b, a float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := math.Atan2(b, a) / rad
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1, h2 := hue(p.B, a1), hue(q.B, a2)
+
+	dL := q.L - p.L
+	dC := c2p - c1p
+	var dh float64
+	if c1p*c2p != 0 {
+		dh = h2 - h1
+		if dh > 180 {
+			dh -= 360
+		} else if dh < -180 {
+			dh += 360
+		}
+	}
+	dH := 2 * math.Sqrt(c1p*c2p) * math.Sin(dh/2*rad)
+
+	lMean := (p.L + q.L) / 2
+	cMean := (c1p + c2p) / 2
+	hMean := h1 + h2
+	if c1p*c2p != 0 {
+		if math.Abs(h1-h2) <= 180 {
+			hMean /= 2
+		} else if h1+h2 < 360 {
+			hMean = (h1 + h2 + 360) / 2
+		} else {
+			hMean = (h1 + h2 - 360) / 2
+		}
+	}
+
+	t := 1 - 0.17*math.Cos((hMean-30)*rad) + 0.24*math.Cos(2*hMean*rad) +
+		0.32*math.Cos((3*hMean+6)*rad) - 0.20*math.Cos((4*hMean-63)*rad)
+	dTheta := 30 * math.Exp(-math.Pow((hMean-275)/25, 2))
+	cMeanP7 := math.Pow(cMean, 7)
+	rc := 2 * math.Sqrt(cMeanP7/(cMeanP7+math.Pow(25, 7)))
+	sl := 1 + 0.015*math.Pow(lMean-50, 2)/math.Sqrt(20+math.Pow(lMean-50, 2))
+	sc := 1 + 0.045*cMean
+	sh := 1 + 0.015*cMean*t
+	rt := -math.Sin(2*dTheta*rad) * rc
+
+	return math.Sqrt(math.Pow(dL/sl, 2) + math.Pow(dC/sc, 2) + math.Pow(dH/sh, 2) +
+		rt*(dC/sc)*(dH/sh))
+}
@@ -0,0 +1,69 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOKLCHReference(t *testing.T) {
+	// Reference values from Björn Ottosson's OKLab definition, as used by
+	// CSS Color 4's oklch().
+	tests := []struct {
+		hex     string
+		L, C, H float64
+	}{
+		{"ffffff", 1, 0, -1},
+		{"000000", 0, 0, -1},
+		{"808080", 0.59987, 0, -1},
+		{"ff0000", 0.62796, 0.25768, 29.234},
+		{"00ff00", 0.86644, 0.29483, 142.495},
+		{"0000ff", 0.45201, 0.31321, 264.052},
+	}
+	for _, tt := range tests {
+		got := MustParseHex(tt.hex).OKLCH()
+		if math.Abs(got.L-tt.L) > 1e-4 || math.Abs(got.C-tt.C) > 1e-4 {
+			t.Errorf("%s: L %.5f C %.5f, want L %.5f C %.5f", tt.hex, got.L, got.C, tt.L, tt.C)
+		}
+		// Hue is meaningless without chroma.
+		if tt.H >= 0 && math.Abs(got.H-tt.H) > 0.01 {
+			t.Errorf("%s: H %.3f, want %.3f", tt.hex, got.H, tt.H)
+		}
+	}
+}
+
+func TestOKLCHRoundTrip(t *testing.T) {
+	// Every 17th value on each channel, plus the built-in palettes.
+	var hexes []string
+	for r := 0; r < 256; r += 17 {
+		for g := 0; g < 256; g += 51 {
+			for b := 0; b < 256; b += 85 {
+				hexes = append(hexes, RGB{float64(r) / 255, float64(g) / 255, float64(b) / 255}.Hex())
+			}
+		}
+	}
+	for _, s := range Palettes {
+		hexes = append(hexes, s.GhosttyBG, s.GhosttyFG, s.CursorColor, s.SelectionBG, s.Accent, s.AccentDim, s.Base)
+	}
+	for _, hex := range hexes {
+		c := MustParseHex(hex)
+		if got := c.OKLCH().RGB().Hex(); got != c.Hex() {
+			t.Errorf("%s → OKLCH → RGB gave %s", c.Hex(), got)
+		}
+	}
+}
+
+func TestOKLCHGamutMapping(t *testing.T) {
+	// Far more chroma than sRGB holds at this lightness and hue.
+	o := OKLCH{L: 0.7, C: 0.4, H: 150}
+	c := o.RGB()
+	if !c.inGamut() {
+		t.Fatalf("RGB() = %+v, out of gamut", c)
+	}
+	back := c.OKLCH()
+	if math.Abs(back.L-o.L) > 1e-3 || math.Abs(back.H-o.H) > 0.5 {
+		t.Errorf("gamut mapping moved L/H: got L %.4f H %.2f, want L %.4f H %.2f", back.L, back.H, o.L, o.H)
+	}
+	if back.C >= o.C {
+		t.Errorf("chroma %.4f was not reduced", back.C)
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// recentWindow is how long after being opened a project's colour still
+// counts as "in use" when choosing a colour for a new project, even if the
+// project has no open windows.
+const recentWindow = 14 * 24 * time.Hour
+
+// pickScheme chooses the colour for a project that has no assignment yet.
+//
+// Schemes no project has been given are preferred. Among those, it picks the
+// one whose base colour is perceptually furthest (CIEDE2000) from every
+// colour currently in view — open sessions and recent assignments — so two
+// active projects don't end up with near-identical colours. If nothing is in
+// view, palette order decides. When every scheme is taken, the one used
+// least recently is reused.
+//
+// With a colour-vision deficiency set, distances are measured as that
+// person sees them, and a scheme that would be hard to tell apart from one
+// in view (closer than color.MinDistinct) is only handed out when there is
+// no distinguishable scheme left to reuse either.
+//
+// The policy's reserved schemes and those at their cap are never chosen,
+// and its preferred schemes are handed out first, in order. If the policy
+// rules out every scheme, pickScheme returns nil.
+func pickScheme(assignments Assignments, active []*color.Scheme, cvd color.Deficiency, policy *Policy) *color.Scheme {
+	counts := schemeCounts(assignments)
+	allowed := func(s *color.Scheme) bool {
+		return policy.allows(s.Name, counts)
+	}
+	distinct := func(s *color.Scheme) bool {
+		return allowed(s) && minDistance(s, active, cvd) >= color.MinDistinct
+	}
+
+	for _, name := range policy.Prefer {
+		s := color.ByName(name)
+		if s != nil && counts[s.Name] == 0 && allowed(s) && (cvd == color.NormalVision || distinct(s)) {
+			return s
+		}
+	}
+
+	var best, closest *color.Scheme
+	bestDist, closestDist := -1.0, -1.0
+	for i := range color.Palettes {
+		s := &color.Palettes[i]
+		if counts[s.Name] > 0 || !allowed(s) {
+			continue
+		}
+		d := minDistance(s, active, cvd)
+		if cvd != color.NormalVision && d < color.MinDistinct {
+			if d > closestDist {
+				closest, closestDist = s, d
+			}
+			continue
+		}
+		if d > bestDist {
+			best, bestDist = s, d
+		}
+	}
+	if best != nil {
+		return best
+	}
+	if cvd != color.NormalVision {
+		if s := leastRecentlyUsed(assignments, distinct); s != nil {
+			return s
+		}
+		if closest != nil {
+			return closest
+		}
+	}
+	return leastRecentlyUsed(assignments, allowed)
+}
+
+// isAssigned reports whether any project has been given s.
+func isAssigned(assignments Assignments, s *color.Scheme) bool {
+	for _, a := range assignments {
+		if a.Scheme == s.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// nextHueScheme generates a scheme for the middle of the widest gap
+// between the palette's hues.
+func nextHueScheme() *color.Scheme {
+	schemes := make([]*color.Scheme, len(color.Palettes))
+	for i := range color.Palettes {
+		schemes[i] = &color.Palettes[i]
+	}
+	return color.HueScheme(color.NextHue(schemes))
+}
+
+// extendPalette appends a scheme from nextHueScheme to the user palette
+// file, so it keeps the same colours even if the generator changes. It is
+// also merged into color.Palettes for the rest of this run.
+func extendPalette(s *color.Scheme) (*color.Scheme, error) {
+	if _, err := AddPalette(s); err != nil {
+		return nil, fmt.Errorf("extending palette: %w", err)
+	}
+	color.Merge(&color.PaletteFile{Schemes: []color.Scheme{*s}})
+	return color.ByName(s.Name), nil
+}
+
+// minDistance returns the distance from s to the closest scheme in others
+// as seen with deficiency cvd, or +Inf if others is empty.
+func minDistance(s *color.Scheme, others []*color.Scheme, cvd color.Deficiency) float64 {
+	d := math.Inf(1)
+	for _, o := range others {
+		d = min(d, color.DistanceFor(s, o, cvd))
+	}
+	return d
+}
+
+// leastRecentlyUsed returns the palette scheme whose projects have gone
+// longest without being opened (see Assignment.LastUsed), considering only
+// schemes keep accepts (all of them if keep is nil). It returns nil if keep
+// rejects every scheme.
+func leastRecentlyUsed(assignments Assignments, keep func(*color.Scheme) bool) *color.Scheme {
+	last := make(map[string]time.Time)
+	for _, a := range assignments {
+		if used := a.LastUsed(); used.After(last[a.Scheme]) {
+			last[a.Scheme] = used
+		}
+	}
+
+	var best *color.Scheme
+	for i := range color.Palettes {
+		s := &color.Palettes[i]
+		if keep != nil && !keep(s) {
+			continue
+		}
+		if best == nil || last[s.Name].Before(last[best.Name]) {
+			best = s
+		}
+	}
+	return best
+}
+
+// activeSchemes returns the schemes of projects that are currently open or
+// were used within recentWindow. Sessions whose windows have all been
+// closed don't count.
+func activeSchemes(assignments Assignments) []*color.Scheme {
+	seen := make(map[string]bool)
+	var out []*color.Scheme
+	add := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		if s := color.ByName(name); s != nil {
+			out = append(out, s)
+		}
+	}
+
+	// Session files are best-effort: if they can't be read, we simply
+	// have less information to go on.
+	sessions, _ := ListSessions()
+	for _, s := range sessions {
+		for _, p := range s.Processes {
+			if IsProcessAlive(p) {
+				add(s.Scheme)
+				break
+			}
+		}
+	}
+
+	cutoff := time.Now().Add(-recentWindow)
+	for _, a := range assignments {
+		if a.LastUsed().After(cutoff) {
+			add(a.Scheme)
+		}
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// TestReuseLeastRecentlyUsed fills the palette and checks that the colour
+// reused is the one whose project has gone longest unopened, not the one
+// assigned longest ago.
+func TestReuseLeastRecentlyUsed(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	assignments := make(Assignments)
+	for i, s := range color.Palettes {
+		assignments[fmt.Sprintf("/p/%s", s.Name)] = Assignment{
+			Scheme:       s.Name,
+			AssignedAt:   now.Add(-time.Duration(30+i) * day),
+			LastOpenedAt: now.Add(-time.Duration(i) * day),
+		}
+	}
+	first, second := color.Palettes[0].Name, color.Palettes[1].Name
+	// The first colour was assigned longest ago but is opened every day;
+	// the second was assigned recently and not opened since.
+	assignments["/p/"+first] = Assignment{Scheme: first, AssignedAt: now.Add(-365 * day), LastOpenedAt: now}
+	assignments["/p/"+second] = Assignment{Scheme: second, AssignedAt: now.Add(-20 * day)}
+
+	if got := leastRecentlyUsed(assignments, nil); got == nil || got.Name != second {
+		t.Errorf("leastRecentlyUsed = %v, want %s", got, second)
+	}
+	if got := pickScheme(assignments, nil, color.NormalVision, &Policy{}); got == nil || got.Name != second {
+		t.Errorf("pickScheme = %v, want %s", got, second)
+	}
+}
+
+func TestLastUsed(t *testing.T) {
+	assigned := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	opened := assigned.Add(48 * time.Hour)
+	tests := []struct {
+		name string
+		a    Assignment
+		want time.Time
+	}{
+		{"never opened", Assignment{AssignedAt: assigned}, assigned},
+		{"opened since", Assignment{AssignedAt: assigned, LastOpenedAt: opened}, opened},
+		{"reassigned after opening", Assignment{AssignedAt: opened, LastOpenedAt: assigned}, opened},
+	}
+	for _, tt := range tests {
+		if got := tt.a.LastUsed(); !got.Equal(tt.want) {
+			t.Errorf("%s: LastUsed() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestExtendPaletteKeepsPersisted checks that schemes added by
+// extend_palette keep their names and colours once saved: later
+// extensions pick new hues, and the saved entry wins over regenerating
+// the scheme from its name.
+func TestExtendPaletteKeepsPersisted(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeEnv, home)
+	t.Cleanup(resetPalettes())
+
+	var names []string
+	for range 3 {
+		s, err := extendPalette(nextHueScheme())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slices.Contains(names, s.Name) {
+			t.Fatalf("extended with %s twice", s.Name)
+		}
+		names = append(names, s.Name)
+	}
+
+	// Change the first saved scheme, as tuning it or a change to the
+	// generator would, then load the file afresh.
+	path := filepath.Join(home, palettesFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := fmt.Sprintf("base         = %q", color.ByName(names[0]).Base)
+	if !bytes.Contains(data, []byte(base)) {
+		t.Fatalf("%s does not contain %s", path, base)
+	}
+	data = bytes.Replace(data, []byte(base), []byte(`base         = "#123456"`), 1)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resetPalettes()()
+	if err := LoadPalettes(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := color.Names(); !slices.Equal(got[len(got)-3:], names) {
+		t.Errorf("palette ends with %q, want %q", got[len(got)-3:], names)
+	}
+	if got := color.ByName(names[0]).Base; got != "#123456" {
+		t.Errorf("%s has base %s after reloading, want the saved #123456", names[0], got)
+	}
+	if next := nextHueScheme(); slices.Contains(names, next.Name) {
+		t.Errorf("next extension would reuse %s", next.Name)
+	}
+}
@@ -2,37 +2,61 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/desktop"
 )
 
-const configDir = ".config/workspace-colours"
 const assignmentsFile = "assignments.json"
 
 // Assignment records which color scheme a project directory was given.
 type Assignment struct {
 	Scheme     string    `json:"scheme"`
 	AssignedAt time.Time `json:"assigned_at"`
+	// LastOpenedAt is when the project was last launched, and OpenCount
+	// how many times it has been.
+	LastOpenedAt time.Time `json:"last_opened_at,omitzero"`
+	OpenCount    int       `json:"open_count,omitempty"`
+	// Appearance overrides the global light/dark setting for this project.
+	Appearance color.Appearance `json:"appearance,omitempty"`
+	// Paths lists the directories a project keyed by git identity has been
+	// opened at.
+	Paths []string `json:"paths,omitempty"`
 }
 
-// Assignments maps absolute project paths to their color assignments.
+// Assignments maps project keys — absolute paths, or git identities (see
+// Identity) — to their color assignments.
 type Assignments map[string]Assignment
 
-// configPath returns the full path to the assignments file.
-func configPath() (string, error) {
-	home, err := os.UserHomeDir()
+// assignmentsFileV1 is the on-disk layout of the assignments file.
+type assignmentsFileV1 struct {
+	Version     int         `json:"version"`
+	Assignments Assignments `json:"assignments"`
+}
+
+// configFile returns the full path to a file in the config directory.
+func configFile(name string) (string, error) {
+	dir, err := ConfigDir()
 	if err != nil {
-		return "", fmt.Errorf("finding home directory: %w", err)
+		return "", err
 	}
-	return filepath.Join(home, configDir, assignmentsFile), nil
+	return filepath.Join(dir, name), nil
+}
+
+// configPath returns the full path to the assignments file.
+func configPath() (string, error) {
+	return configFile(assignmentsFile)
 }
 
 // Load reads the assignments file from disk. Returns an empty map if the
-// file doesn't exist yet.
+// file doesn't exist yet. Files in an older format are upgraded in memory
+// (Migrate rewrites them on disk); a file from a newer version of workspace
+// is an error wrapping ErrNewerVersion.
 func Load() (Assignments, error) {
 	path, err := configPath()
 	if err != nil {
@@ -47,111 +71,272 @@ func Load() (Assignments, error) {
 		return nil, fmt.Errorf("reading %s: %w", path, err)
 	}
 
-	var a Assignments
-	if err := json.Unmarshal(data, &a); err != nil {
+	var f assignmentsFileV1
+	if err := assignmentsSchema.decode(data, &f); err != nil {
 		return nil, fmt.Errorf("parsing %s: %w", path, err)
 	}
-	return a, nil
+	if f.Assignments == nil {
+		f.Assignments = make(Assignments)
+	}
+	return f.Assignments, nil
 }
 
-// Save writes the assignments to disk, creating the directory if needed.
+// Save writes the assignments to disk atomically, creating the directory if
+// needed. Callers that read, change and write the assignments should use
+// update instead, so concurrent launches don't overwrite each other.
 func Save(a Assignments) error {
 	path, err := configPath()
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
+	f := assignmentsFileV1{Version: assignmentsSchema.current(), Assignments: a}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding assignments: %w", err)
 	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// errUnchanged can be returned by an update callback to skip the write when
+// it has nothing to change.
+var errUnchanged = errors.New("assignments unchanged")
 
-	data, err := json.MarshalIndent(a, "", "  ")
+// update loads the assignments, passes them to fn and saves the result, all
+// while holding the assignments lock. Nothing is written if fn fails or
+// returns errUnchanged.
+func update(fn func(Assignments) error) error {
+	path, err := configPath()
 	if err != nil {
-		return fmt.Errorf("encoding assignments: %w", err)
+		return err
 	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return fmt.Errorf("writing %s: %w", path, err)
+	assignments, err := Load()
+	if err != nil {
+		return err
 	}
-	return nil
+	if err := fn(assignments); err != nil {
+		if err == errUnchanged {
+			return nil
+		}
+		return err
+	}
+	return Save(assignments)
 }
 
-// GetOrAssign looks up the color for a project. If none is assigned, it picks
-// the next available color from the palette and persists the choice.
+// GetOrAssign looks up the color for a project. If none is assigned, it
+// chooses one (see decide) and persists the choice.
 // If forceName is non-empty, it overrides any existing assignment.
+//
+// The assignment is keyed by the project's identity (see Identity), and
+// the path it was opened at is recorded alongside, as is the launch itself
+// (LastOpenedAt and OpenCount). With auto_prune set, stale assignments are
+// dropped first (see Prune), so their colours can be reused.
 func GetOrAssign(projectDir string, forceName string) (*color.Scheme, error) {
-	absDir, err := filepath.Abs(projectDir)
-	if err != nil {
-		return nil, fmt.Errorf("resolving path: %w", err)
-	}
-
-	assignments, err := Load()
+	settings, err := LoadSettings()
 	if err != nil {
 		return nil, err
 	}
 
-	// If a specific color was requested, use it.
-	if forceName != "" {
-		scheme := color.ByName(forceName)
-		if scheme == nil {
-			return nil, fmt.Errorf("unknown color scheme %q (available: %v)", forceName, color.Names())
+	var scheme *color.Scheme
+	var pruned []Stale
+	err = update(func(assignments Assignments) error {
+		// The palette was loaded before the lock was taken, and another
+		// launch may have extended it since.
+		if err := LoadPalettes(); err != nil {
+			return err
 		}
-		assignments[absDir] = Assignment{Scheme: forceName, AssignedAt: time.Now()}
-		if err := Save(assignments); err != nil {
-			return nil, err
+		// A forced colour belongs to the directory itself, not to the
+		// project it would inherit from.
+		key, dir, manifest, _, err := locateManifest(assignments, projectDir, settings, forceName == "")
+		if err != nil {
+			return err
+		}
+		assignments.seenAt(key, dir)
+		now := time.Now()
+		if settings.AutoPrune {
+			pruned = findStale(assignments, settings.PruneAfter, now, key)
+			for _, s := range pruned {
+				delete(assignments, s.Key)
+			}
 		}
-		return scheme, nil
-	}
 
-	// Check for existing assignment.
-	if a, ok := assignments[absDir]; ok {
-		scheme := color.ByName(a.Scheme)
-		if scheme != nil {
-			return scheme, nil
+		a := assignments[key]
+		if forceName != "" {
+			// A specific color was requested.
+			if scheme = color.ByName(forceName); scheme == nil {
+				return fmt.Errorf("unknown color scheme %q (available: %v)", forceName, color.Names())
+			}
+		} else {
+			d := decide(assignments, key, dir, settings, manifest)
+			if d.Scheme == nil {
+				return fmt.Errorf("%s; set extend_palette or raise a cap", d.Reason)
+			}
+			scheme = d.Scheme
+			if d.generated {
+				if scheme, err = extendPalette(scheme); err != nil {
+					return err
+				}
+			}
 		}
-		// Scheme name in file no longer exists in palette — reassign.
+		if a.Scheme != scheme.Name {
+			a.Scheme, a.AssignedAt = scheme.Name, now
+		}
+		// Every launch counts as a use, so pruning by age only removes
+		// projects that really have gone unopened.
+		a.LastOpenedAt = now
+		a.OpenCount++
+		assignments[key] = a
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	// Session files are best-effort; a leftover one does no harm.
+	if len(pruned) > 0 {
+		pruneSessions(pruned, false)
+	}
+	return scheme, nil
+}
 
-	// Find which colors are already in use.
-	used := make(map[string]bool)
-	for _, a := range assignments {
-		used[a.Scheme] = true
+// rekey moves an assignment stored under absDir to key: one from before a
+// git identity was switched on, or from a path through a symlink. If key
+// already has an assignment that one wins. It reports whether anything
+// changed.
+func rekey(assignments Assignments, key, absDir string) bool {
+	if key == absDir {
+		return false
+	}
+	a, ok := assignments[absDir]
+	if !ok {
+		return false
+	}
+	if _, taken := assignments[key]; !taken {
+		assignments[key] = a
 	}
+	delete(assignments, absDir)
+	return true
+}
 
-	// Pick the first unused color.
-	for _, s := range color.Palettes {
-		if !used[s.Name] {
-			assignments[absDir] = Assignment{Scheme: s.Name, AssignedAt: time.Now()}
-			if err := Save(assignments); err != nil {
-				return nil, err
-			}
-			return color.ByName(s.Name), nil
-		}
+// lookup returns the assignment for a project, and whether it has one.
+func lookup(projectDir string) (Assignment, bool, error) {
+	settings, err := LoadSettings()
+	if err != nil {
+		return Assignment{}, false, err
+	}
+	assignments, err := Load()
+	if err != nil {
+		return Assignment{}, false, err
+	}
+	key, _, _, err := locate(assignments, projectDir, settings, true)
+	if err != nil {
+		return Assignment{}, false, err
 	}
+	a, ok := assignments[key]
+	return a, ok, nil
+}
 
-	// All colors are in use — recycle from the beginning.
-	scheme := &color.Palettes[0]
-	assignments[absDir] = Assignment{Scheme: scheme.Name, AssignedAt: time.Now()}
-	if err := Save(assignments); err != nil {
+// Lookup returns the scheme assigned to a project, or nil if it has none.
+// Unlike GetOrAssign it never creates an assignment.
+func Lookup(projectDir string) (*color.Scheme, error) {
+	a, ok, err := lookup(projectDir)
+	if err != nil || !ok {
 		return nil, err
 	}
-	return scheme, nil
+	return color.ByName(a.Scheme), nil
 }
 
-// Reset removes the color assignment for a project.
+// Reset removes the color assignment for a project. A directory that
+// only inherits its colour has none of its own to remove, so that is an
+// error naming the directory to reset instead.
 func Reset(projectDir string) error {
-	absDir, err := filepath.Abs(projectDir)
+	settings, err := LoadSettings()
 	if err != nil {
-		return fmt.Errorf("resolving path: %w", err)
+		return err
 	}
+	return update(func(assignments Assignments) error {
+		key, dir, _, err := locate(assignments, projectDir, settings, true)
+		if err != nil {
+			return err
+		}
+		if real, _, _ := resolveDir(projectDir); dir != real {
+			return fmt.Errorf("%s inherits its color from %s; reset that instead", projectDir, dir)
+		}
+		delete(assignments, key)
+		return nil
+	})
+}
 
-	assignments, err := Load()
+// SetAppearance records a per-project light/dark preference. An empty
+// appearance clears it, so the project follows the global setting again.
+// Like Reset, it refuses a directory that inherits its colour, rather than
+// quietly changing the project it inherits from.
+func SetAppearance(projectDir string, appearance color.Appearance) error {
+	settings, err := LoadSettings()
 	if err != nil {
 		return err
 	}
+	return update(func(assignments Assignments) error {
+		key, dir, _, err := locate(assignments, projectDir, settings, true)
+		if err != nil {
+			return err
+		}
+		if real, _, _ := resolveDir(projectDir); dir != real {
+			return fmt.Errorf("%s inherits its color from %s; set the appearance there instead", projectDir, dir)
+		}
+		a, ok := assignments[key]
+		if !ok {
+			return fmt.Errorf("%s has no color assignment", dir)
+		}
+		a.Appearance = appearance
+		assignments[key] = a
+		return nil
+	})
+}
 
-	delete(assignments, absDir)
-	return Save(assignments)
+// AppearanceSetting returns a project's appearance setting as configured:
+// its own preference if it has one, else the global setting, else dark. The
+// result may be color.Auto.
+func AppearanceSetting(projectDir string) (color.Appearance, error) {
+	a, _, err := lookup(projectDir)
+	if err != nil {
+		return "", err
+	}
+	if a.Appearance != "" {
+		return a.Appearance, nil
+	}
+
+	settings, err := LoadSettings()
+	if err != nil {
+		return "", err
+	}
+	if settings.Appearance != "" {
+		return settings.Appearance, nil
+	}
+	return color.Dark, nil
+}
+
+// ResolveAppearance returns the variant to use for a project, asking the
+// desktop when the setting is auto. If the desktop preference can't be
+// read, it returns color.Dark along with the error.
+func ResolveAppearance(projectDir string) (color.Appearance, error) {
+	a, err := AppearanceSetting(projectDir)
+	if err != nil {
+		return color.Dark, err
+	}
+	if a != color.Auto {
+		return a, nil
+	}
+	d, err := desktop.Appearance()
+	if err != nil {
+		return color.Dark, fmt.Errorf("following desktop appearance: %w", err)
+	}
+	return d, nil
 }
 
 // List returns all current assignments.
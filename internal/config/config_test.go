@@ -0,0 +1,135 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// launchEnv names the project directory a helper process assigns a colour
+// to, and startEnv a file it waits for, so that all of them race.
+const (
+	launchEnv = "WORKSPACE_COLOURS_TEST_LAUNCH"
+	startEnv  = "WORKSPACE_COLOURS_TEST_START"
+)
+
+// startTimeout is how long a helper process waits for the start file.
+const startTimeout = 10 * time.Second
+
+// TestHelperLaunch is not a real test: TestGetOrAssignConcurrent runs it
+// in separate processes, each doing what a launch does.
+func TestHelperLaunch(t *testing.T) {
+	dir := os.Getenv(launchEnv)
+	if dir == "" {
+		return
+	}
+	// Give up if the test never gets as far as starting the race.
+	deadline := time.Now().Add(startTimeout)
+	for {
+		if _, err := os.Stat(os.Getenv(startEnv)); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintf(os.Stderr, "no start signal after %v\n", startTimeout)
+			os.Exit(1)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := LoadPalettes(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	s, err := GetOrAssign(dir, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(s.Name)
+	os.Exit(0)
+}
+
+// TestGetOrAssignConcurrent launches more projects at once than there are
+// built-in colours, in separate processes as concurrent launches would be,
+// and checks that none of their assignments is lost. With extend_palette
+// each must also get a colour of its own.
+func TestGetOrAssignConcurrent(t *testing.T) {
+	const n = 12
+	for _, extend := range []bool{false, true} {
+		t.Run(fmt.Sprintf("extend_palette=%v", extend), func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv(HomeEnv, home)
+			if extend {
+				if err := os.WriteFile(filepath.Join(home, "config.toml"), []byte("extend_palette = true\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			start := filepath.Join(home, "start")
+			projects := t.TempDir()
+
+			cmds := make([]*exec.Cmd, n)
+			outs := make([]*bytes.Buffer, n)
+			for i := range cmds {
+				dir := filepath.Join(projects, fmt.Sprintf("p%02d", i))
+				if err := os.Mkdir(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				cmd := exec.Command(os.Args[0], "-test.run=^TestHelperLaunch$")
+				cmd.Env = append(os.Environ(), launchEnv+"="+dir, startEnv+"="+start)
+				outs[i] = &bytes.Buffer{}
+				cmd.Stdout, cmd.Stderr = outs[i], outs[i]
+				if err := cmd.Start(); err != nil {
+					t.Fatal(err)
+				}
+				// Don't leave helpers behind if the test stops early.
+				t.Cleanup(func() { cmd.Process.Kill() })
+				cmds[i] = cmd
+			}
+			if err := os.WriteFile(start, nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			for i, cmd := range cmds {
+				if err := cmd.Wait(); err != nil {
+					t.Errorf("launch %d: %v: %s", i, err, bytes.TrimSpace(outs[i].Bytes()))
+				}
+			}
+			if t.Failed() {
+				return
+			}
+
+			t.Cleanup(resetPalettes())
+			if err := LoadPalettes(); err != nil {
+				t.Fatal(err)
+			}
+			assignments, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(assignments) != n {
+				t.Errorf("%d assignments survived, want %d", len(assignments), n)
+			}
+			schemes := make(map[string]bool)
+			for key, a := range assignments {
+				if color.ByName(a.Scheme) == nil {
+					t.Errorf("%s has scheme %q, which is not in the palette", key, a.Scheme)
+				}
+				schemes[a.Scheme] = true
+			}
+			if extend && len(schemes) != n {
+				t.Errorf("%d distinct colours for %d projects with extend_palette", len(schemes), n)
+			}
+		})
+	}
+}
+
+// resetPalettes returns a function that restores color.Palettes, for a
+// test that merges a user palette file into it.
+func resetPalettes() func() {
+	saved := append([]color.Scheme(nil), color.Palettes...)
+	return func() { color.Palettes = saved }
+}
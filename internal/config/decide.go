@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// Decision is how a project's colour was, or would be, chosen.
+type Decision struct {
+	// Key is what the project's assignment is stored under.
+	Key string
+	// InheritedFrom is the directory whose colour a subdirectory shares
+	// (see Settings.Inherit), or "" if it has its own.
+	InheritedFrom string
+	Scheme        *color.Scheme
+	// Reason says what decided the colour.
+	Reason string
+	// Notes mention anything else that would have applied, and why it
+	// didn't.
+	Notes []string
+
+	// generated is set when Scheme is a new hue that still has to be
+	// added to the palette.
+	generated bool
+}
+
+func (d *Decision) note(format string, args ...any) {
+	d.Notes = append(d.Notes, fmt.Sprintf(format, args...))
+}
+
+// decide works out the colour for the project stored under key, opened at
+// absDir, without changing anything. In order, it uses:
+//
+//  0. the colour in the project's manifest, which is committed with the
+//     repository and so shared by everyone who works on it, unless the
+//     policy reserves it;
+//  1. the project's existing assignment, unless it was released (see
+//     Settings.ReleaseAfter) and another project has since taken its
+//     colour;
+//  2. a shade of its family's hue, if it is in a [[family]];
+//  3. the first matching [[rule]] in config.toml;
+//  4. automatic assignment (see pickScheme).
+func decide(assignments Assignments, key, absDir string, settings *Settings, manifest *Manifest) *Decision {
+	d := &Decision{Key: key}
+	team := teamScheme(manifest, settings)
+	if team == nil && manifest.Color != "" {
+		d.note("ignored the team colour in %s: %s is reserved ([policy]), so only given with --color", manifest.Path, manifest.Color)
+	}
+	if team != nil {
+		d.Scheme = team
+		d.Reason = fmt.Sprintf("team colour in %s", manifest.Path)
+		if a, ok := assignments[key]; ok && a.Scheme != d.Scheme.Name {
+			d.note("replaces your own assignment, %s", a.Scheme)
+		}
+		return d
+	}
+	rule, n := matchRule(settings.Rules, absDir)
+	family := settings.FamilyOf(absDir)
+	// Colours are chosen among those other projects still hold.
+	now := time.Now()
+	live := liveAssignments(assignments, key, settings.ReleaseAfter, now)
+
+	if a, ok := assignments[key]; ok && a.Scheme != "" {
+		s := color.ByName(a.Scheme)
+		expired := released(a, settings.ReleaseAfter, now)
+		switch {
+		case s == nil:
+			// Scheme name in file no longer exists in palette — reassign.
+			d.note("assigned %q, which is no longer in the palette", a.Scheme)
+		case expired && isAssigned(live, s):
+			d.note("its colour, %s, was released after %s unused (release_after) and another project now holds it", s.Name, formatAge(settings.ReleaseAfter))
+		default:
+			d.Scheme = s
+			d.Reason = fmt.Sprintf("existing assignment (since %s)", a.AssignedAt.Format("2006-01-02"))
+			if expired {
+				d.note("released after %s unused (release_after), but no other project has taken it", formatAge(settings.ReleaseAfter))
+			}
+			if family != nil && !color.IsShadeOf(s.Name, family.Hue) {
+				d.note("in family %q, but that only applies to new projects; --reset-color to apply it", family.Name)
+			} else if family == nil && rule != nil && rule.Color != s.Name {
+				d.note("rule %d (%s) matches, but only applies to new projects; --reset-color to apply it", n, rule)
+			}
+			return d
+		}
+	}
+
+	if family != nil {
+		var free bool
+		d.Scheme, free = pickShade(live, family)
+		if free {
+			d.Reason = fmt.Sprintf("family %q in config.toml (line %d): the next unused shade of hue %d", family.Name, family.Line, family.Hue)
+		} else {
+			d.Reason = fmt.Sprintf("family %q in config.toml (line %d): every shade of hue %d is taken, so the least recently assigned is reused", family.Name, family.Line, family.Hue)
+		}
+		if rule != nil {
+			d.note("rule %d (%s) also matches, but the family takes precedence", n, rule)
+		}
+		return d
+	}
+
+	if rule != nil {
+		d.Scheme = color.ByName(rule.Color)
+		d.Reason = fmt.Sprintf("rule %d in config.toml (line %d): %s", n, rule.Line, rule)
+		return d
+	}
+
+	policy := &settings.Policy
+	d.Scheme = pickScheme(live, activeSchemes(live), settings.CVD, policy)
+	switch {
+	case d.Scheme != nil && !isAssigned(live, d.Scheme) && slices.Contains(policy.Prefer, d.Scheme.Name):
+		d.Reason = "automatic: the first unused colour in the preferred order ([policy] prefer)"
+	case d.Scheme != nil && !isAssigned(live, d.Scheme):
+		d.Reason = "automatic: the unused colour most distinct from those in view"
+	case settings.ExtendPalette:
+		d.Scheme, d.generated = nextHueScheme(), true
+		d.Reason = "automatic: every colour is taken, so a new hue was generated (extend_palette)"
+	case d.Scheme == nil:
+		d.Reason = "no colour left: every scheme is reserved or at its cap ([policy])"
+	default:
+		d.Reason = "automatic: every colour is taken, so the least recently used is reused"
+	}
+	policy.explain(d, live)
+	if settings.CVD != color.NormalVision {
+		d.note("distances measured as seen with %s", settings.CVD)
+	}
+	return d
+}
+
+// Explain reports how projectDir's colour would be chosen if it were
+// opened now without --color, without changing anything.
+func Explain(projectDir string) (*Decision, error) {
+	settings, err := LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	key, dir, manifest, _, err := locateManifest(assignments, projectDir, settings, true)
+	if err != nil {
+		return nil, err
+	}
+	d := decide(assignments, key, dir, settings, manifest)
+	if real, _, _ := resolveDir(projectDir); dir != real {
+		d.InheritedFrom = dir
+	}
+	return d, nil
+}
+
+// locateManifest is locate for a project that may declare its own colour
+// in its manifest. A directory whose manifest sets one (see teamScheme) is
+// always its own project; otherwise the manifest read is that of the project found.
+func locateManifest(assignments Assignments, projectDir string, settings *Settings, inherit bool) (key, dir string, manifest *Manifest, changed bool, err error) {
+	real, _, err := resolveDir(projectDir)
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	if manifest, err = LoadManifest(real); err != nil {
+		return "", "", nil, false, err
+	}
+	key, dir, changed, err = locate(assignments, projectDir, settings, inherit && teamScheme(manifest, settings) == nil)
+	if err != nil {
+		return "", "", nil, false, err
+	}
+	if dir != real {
+		if manifest, err = LoadManifest(dir); err != nil {
+			return "", "", nil, false, err
+		}
+	}
+	return key, dir, manifest, changed, nil
+}
+
+// teamScheme returns the colour a project's manifest gives it, or nil if it
+// sets none or the one it sets is reserved (see Policy).
+func teamScheme(manifest *Manifest, settings *Settings) *color.Scheme {
+	if manifest.Color == "" {
+		return nil
+	}
+	s := color.ByName(manifest.Color)
+	if s == nil || settings.Policy.IsReserved(s.Name) {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// Family is a named group of related projects that share one hue, each
+// member getting its own shade of it. Families come from [[family]] tables
+// in config.toml.
+type Family struct {
+	Name string
+	// Hue is the family's hue in OKLCH degrees.
+	Hue int
+	// Parent, if set, makes every directory directly inside it a member.
+	Parent string
+	// Members are further member directories.
+	Members []string
+	// Line is where the family starts in config.toml.
+	Line int
+}
+
+// Contains reports whether the absolute directory dir is a member.
+func (f *Family) Contains(dir string) bool {
+	dir = filepath.Clean(dir)
+	if f.Parent != "" && filepath.Dir(dir) == f.Parent && dir != f.Parent {
+		return true
+	}
+	for _, m := range f.Members {
+		if m == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// FamilyOf returns the first family dir belongs to, or nil.
+func (s *Settings) FamilyOf(dir string) *Family {
+	for i := range s.Families {
+		if s.Families[i].Contains(dir) {
+			return &s.Families[i]
+		}
+	}
+	return nil
+}
+
+// FamilyOfAssignment returns the family of the project stored under key,
+// judged by the directories it has been opened at, or nil.
+func (s *Settings) FamilyOfAssignment(key string, a Assignment) *Family {
+	dirs := a.Paths
+	if len(dirs) == 0 {
+		dirs = []string{key}
+	}
+	for _, d := range dirs {
+		if f := s.FamilyOf(d); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// pickShade returns the family's first shade no project has, or if all
+// are taken the one assigned least recently.
+func pickShade(assignments Assignments, f *Family) (*color.Scheme, bool) {
+	taken := make(map[string]Assignment)
+	for _, a := range assignments {
+		if color.IsShadeOf(a.Scheme, f.Hue) {
+			if prev, ok := taken[a.Scheme]; !ok || a.AssignedAt.After(prev.AssignedAt) {
+				taken[a.Scheme] = a
+			}
+		}
+	}
+	var oldest *color.Scheme
+	var oldestAt Assignment
+	for n := 1; n <= color.ShadeCount(); n++ {
+		s := color.ShadeScheme(f.Hue, n)
+		a, ok := taken[s.Name]
+		if !ok {
+			return s, true
+		}
+		if oldest == nil || a.AssignedAt.Before(oldestAt.AssignedAt) {
+			oldest, oldestAt = s, a
+		}
+	}
+	return oldest, false
+}
+
+func parseFamilies(root *toml.Table) ([]Family, error) {
+	tables, err := root.Tables("family")
+	if err != nil {
+		return nil, err
+	}
+	var families []Family
+	seen := make(map[string]bool)
+	for _, t := range tables {
+		if err := t.CheckKeys("name", "hue", "color", "parent", "members"); err != nil {
+			return nil, err
+		}
+		f := Family{Line: t.Line}
+		if f.Name, err = t.String("name"); err != nil {
+			return nil, err
+		}
+		if f.Name == "" {
+			return nil, toml.Errorf(t.Line, "family needs a name")
+		}
+		if seen[f.Name] {
+			return nil, toml.Errorf(t.Line, "duplicate family %q", f.Name)
+		}
+		seen[f.Name] = true
+
+		base, err := t.String("color")
+		if err != nil {
+			return nil, err
+		}
+		switch v := t.Get("hue"); {
+		case v != nil && base != "":
+			return nil, toml.Errorf(t.Line, "family %q has both hue and color; use one", f.Name)
+		case v != nil:
+			hue, err := v.AsInt()
+			if err != nil {
+				return nil, err
+			}
+			if hue < 0 || hue >= 360 {
+				return nil, toml.Errorf(v.Line, "hue %d out of range (want 0-359)", hue)
+			}
+			f.Hue = int(hue)
+		case base != "":
+			s := color.ByName(base)
+			if s == nil {
+				return nil, toml.Errorf(t.Line, "unknown color scheme %q (available: %v)", base, color.Names())
+			}
+			rgb, err := color.ParseHex(s.Base)
+			if err != nil {
+				return nil, toml.Errorf(t.Line, "color %q: %v", base, err)
+			}
+			f.Hue = int(math.Round(rgb.OKLCH().H)) % 360
+		default:
+			return nil, toml.Errorf(t.Line, "family %q needs a hue or color", f.Name)
+		}
+
+		parent, err := t.String("parent")
+		if err != nil {
+			return nil, err
+		}
+		if parent != "" {
+			if f.Parent, err = expandDir(parent); err != nil {
+				return nil, toml.Errorf(t.Line, "%v", err)
+			}
+		}
+		members, err := t.Strings("members")
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			dir, err := expandDir(m)
+			if err != nil {
+				return nil, toml.Errorf(t.Line, "%v", err)
+			}
+			f.Members = append(f.Members, dir)
+		}
+		if f.Parent == "" && len(f.Members) == 0 {
+			return nil, toml.Errorf(t.Line, "family %q needs a parent or members", f.Name)
+		}
+		families = append(families, f)
+	}
+	return families, nil
+}
+
+// expandDir expands a leading "~/" and cleans an absolute directory path.
+func expandDir(dir string) (string, error) {
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("finding home directory: %w", err)
+		}
+		dir = filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("path %q must be absolute or start with ~/", dir)
+	}
+	return filepath.Clean(dir), nil
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Identity selects what assignments are keyed by.
+type Identity string
+
+const (
+	// IdentityPath keys assignments by absolute directory path.
+	IdentityPath Identity = "path"
+	// IdentityRemote keys a repository by its normalised remote URL, so
+	// clones anywhere — on any machine — share a colour. Repositories
+	// without a remote fall back to IdentityRootCommit.
+	IdentityRemote Identity = "remote"
+	// IdentityRootCommit keys a repository by its first commit, which
+	// survives a change of remote.
+	IdentityRootCommit Identity = "root-commit"
+)
+
+// Prefixes of identity keys, so they can't be mistaken for paths.
+const (
+	remotePrefix     = "git:"
+	rootCommitPrefix = "git-root:"
+)
+
+// ParseIdentity parses an identity setting.
+func ParseIdentity(s string) (Identity, error) {
+	switch i := Identity(s); i {
+	case IdentityPath, IdentityRemote, IdentityRootCommit:
+		return i, nil
+	}
+	return "", fmt.Errorf("invalid identity %q (want path, remote or root-commit)", s)
+}
+
+// projectKey returns the key absDir's assignment is stored under. Only the
+// top level of a git repository gets a git identity; anything else, or a
+// repository with nothing to identify it by, is keyed by its path.
+func projectKey(absDir string, mode Identity) string {
+	if mode != IdentityRemote && mode != IdentityRootCommit {
+		return absDir
+	}
+	top, err := gitOutput(absDir, "rev-parse", "--show-toplevel")
+	if err != nil || !samePath(top, absDir) {
+		return absDir
+	}
+	if mode == IdentityRemote {
+		if url := gitRemoteURL(absDir); url != "" {
+			return remotePrefix + normalizeRemote(url)
+		}
+	}
+	if root := gitRootCommit(absDir); root != "" {
+		return rootCommitPrefix + root
+	}
+	return absDir
+}
+
+// seenAt records that the project under key was opened at absDir, and
+// reports whether that is new. Paths are only kept for identity keys,
+// where they differ from the key.
+func (a Assignments) seenAt(key, absDir string) bool {
+	if key == absDir {
+		return false
+	}
+	as := a[key]
+	for _, p := range as.Paths {
+		if p == absDir {
+			return false
+		}
+	}
+	as.Paths = append(as.Paths, absDir)
+	sort.Strings(as.Paths)
+	a[key] = as
+	return true
+}
+
+// DisplayName returns a short name for the project stored under key: the
+// base name of the directory it was opened at.
+func (a Assignment) DisplayName(key string) string {
+	if len(a.Paths) > 0 {
+		return filepath.Base(a.Paths[0])
+	}
+	return filepath.Base(key)
+}
+
+// gitRemoteURL returns the URL of the "origin" remote, or of the first
+// remote if there is no origin.
+func gitRemoteURL(dir string) string {
+	if url, err := gitOutput(dir, "remote", "get-url", "origin"); err == nil && url != "" {
+		return url
+	}
+	remotes, err := gitOutput(dir, "remote")
+	if err != nil || remotes == "" {
+		return ""
+	}
+	url, _ := gitOutput(dir, "remote", "get-url", strings.Fields(remotes)[0])
+	return url
+}
+
+// gitRootCommit returns the hash of the repository's root commit. A history
+// with several roots (from merged projects) uses the smallest hash, so the
+// answer doesn't depend on traversal order.
+func gitRootCommit(dir string) string {
+	out, err := gitOutput(dir, "rev-list", "--max-parents=0", "HEAD")
+	if err != nil || out == "" {
+		return ""
+	}
+	roots := strings.Fields(out)
+	sort.Strings(roots)
+	return roots[0]
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// samePath reports whether a and b name the same directory once symlinks
+// are resolved.
+func samePath(a, b string) bool {
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return ra == rb
+}
+
+// normalizeRemote reduces a git remote URL to host/path, so that the HTTPS,
+// SSH and scp-style forms of the same repository compare equal:
+// "git@GitHub.com:Owner/Repo.git" and "https://github.com/Owner/Repo" both
+// become "github.com/Owner/Repo". Only the host is lower-cased; whether
+// paths are case-sensitive is up to the server, and on many they are.
+func normalizeRemote(url string) string {
+	u := strings.TrimSpace(url)
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	} else if i := strings.Index(u, ":"); i >= 0 && !strings.Contains(u[:i], "/") {
+		// scp-style user@host:path
+		u = u[:i] + "/" + u[i+1:]
+	}
+	if i := strings.Index(u, "@"); i >= 0 && i < strings.Index(u+"/", "/") {
+		u = u[i+1:]
+	}
+	host, path, _ := strings.Cut(u, "/")
+	// Drop an explicit port; ssh://host:22/x and host:x are the same repo.
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return strings.ToLower(host) + "/" + path
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// resolveDir returns dir as an absolute path with symlinks evaluated, so a
+// project reached through a symlink shares its colour with the directory
+// it points to, and the absolute path as given. A path that can't be
+// evaluated (one that no longer exists, say) is only made absolute.
+func resolveDir(dir string) (real, abs string, err error) {
+	abs, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("resolving path: %w", err)
+	}
+	if real, err = filepath.EvalSymlinks(abs); err != nil {
+		return abs, abs, nil
+	}
+	return real, abs, nil
+}
+
+// locate finds the project that projectDir belongs to: the directory whose
+// assignment it uses, and the key that assignment is stored under.
+// Assignments stored under an older key for that directory — its path
+// through a symlink, or its path from before a git identity was switched
+// on — are moved to the current one, and changed reports whether any
+// were. With inherit set, the directory is found as inheritedDir
+// describes; otherwise it is projectDir itself.
+func locate(assignments Assignments, projectDir string, settings *Settings, inherit bool) (key, dir string, changed bool, err error) {
+	dir, abs, err := resolveDir(projectDir)
+	if err != nil {
+		return "", "", false, err
+	}
+	changed = rekey(assignments, dir, abs)
+	if inherit && settings.Inherit {
+		dir = inheritedDir(assignments, dir)
+	}
+	key = projectKey(dir, settings.Identity)
+	changed = rekey(assignments, key, dir) || changed
+	return key, dir, changed, nil
+}
+
+// inheritedDir walks up from the resolved directory dir and returns the
+// first directory that is a project of its own:
+//
+//   - one with an assignment;
+//   - one whose manifest sets inherit = false;
+//   - the top level of the git repository dir is in, so that a repository
+//     is one project however deep it is opened.
+//
+// The walk never goes above the repository, and never reaches the home
+// directory or the filesystem root, which would otherwise colour
+// everything below them. If nothing is found, dir is its own project.
+func inheritedDir(assignments Assignments, dir string) string {
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		top = ""
+	}
+	home, _ := os.UserHomeDir()
+	if h, err := filepath.EvalSymlinks(home); err == nil {
+		home = h
+	}
+
+	for d := dir; ; d = filepath.Dir(d) {
+		if d != dir && (d == home || d == filepath.Dir(d)) {
+			break
+		}
+		if _, ok := assignments[d]; ok {
+			return d
+		}
+		if m, err := LoadManifest(d); err == nil && m.Inherit != nil && !*m.Inherit {
+			return d
+		}
+		if d == top {
+			return top
+		}
+	}
+	return dir
+}
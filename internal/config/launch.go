@@ -0,0 +1,233 @@
+package config
+
+import (
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// Built-in launch defaults, used when no layer sets a value.
+const (
+	defaultTerminalCount = 2
+	defaultCursor        = true
+	defaultBrowser       = false
+	defaultBorders       = false
+)
+
+// Sources a resolved launch setting can come from, besides a file path.
+const (
+	SourceBuiltIn = "built-in"
+	SourceFlag    = "command line"
+)
+
+// Terminal describes one terminal window.
+type Terminal struct {
+	Label string
+	// Command is run in the window's shell when it opens. Empty means
+	// just a shell.
+	Command string
+}
+
+// LaunchOptions is one layer of launch settings: the [defaults] table of
+// config.toml, a project manifest, or the command-line flags. Nil and empty
+// fields were not set, so a lower layer's value applies.
+type LaunchOptions struct {
+	// TerminalCount is the number of terminal windows. If unset, it is
+	// the number of Terminals given.
+	TerminalCount *int
+	// Terminals labels the first windows and sets what they run.
+	Terminals []Terminal
+	Cursor    *bool
+	Browser   *bool
+	Borders   *bool
+	// Color is a scheme name or hex colour the project should use.
+	Color string
+	// URLs are opened in the themed Firefox window.
+	URLs []string
+}
+
+// Launch is the effective launch settings for a project.
+type Launch struct {
+	TerminalCount int
+	// Terminals configures the first windows; there may be fewer than
+	// TerminalCount, or more (the extra ones are not opened).
+	Terminals []Terminal
+	Cursor    bool
+	Browser   bool
+	Borders   bool
+	Color     string
+	URLs      []string
+
+	// Sources maps each setting, by its config key, to where its value
+	// came from: SourceBuiltIn, SourceFlag or a file path.
+	Sources map[string]string
+	// Manifest is the project's manifest file, or "" if it has none.
+	Manifest string
+}
+
+// ManifestCommands returns the terminal commands that come from the
+// project's manifest. They are only run once it is trusted (see Trusted).
+func (l *Launch) ManifestCommands() []string {
+	if l.Manifest == "" || l.Sources["terminal"] != l.Manifest {
+		return nil
+	}
+	var cmds []string
+	for _, t := range l.Terminals[:min(len(l.Terminals), l.TerminalCount)] {
+		if t.Command != "" {
+			cmds = append(cmds, t.Command)
+		}
+	}
+	return cmds
+}
+
+// DropCommands clears the terminal commands, leaving the windows to open
+// with just a shell.
+func (l *Launch) DropCommands() {
+	terminals := make([]Terminal, len(l.Terminals))
+	for i, t := range l.Terminals {
+		terminals[i] = Terminal{Label: t.Label}
+	}
+	l.Terminals = terminals
+}
+
+// ResolveLaunch works out the launch settings for projectDir. Each layer
+// overrides the one before: built-in defaults, the [defaults] table in
+// config.toml, the project's manifest, then flags.
+func ResolveLaunch(projectDir string, flags *LaunchOptions) (*Launch, error) {
+	settings, err := LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	settingsPath, err := configFile(settingsFile)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := LoadManifest(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Launch{
+		Manifest:      manifest.Path,
+		TerminalCount: defaultTerminalCount,
+		Cursor:        defaultCursor,
+		Browser:       defaultBrowser,
+		Borders:       defaultBorders,
+		Sources: map[string]string{
+			"terminals": SourceBuiltIn,
+			"terminal":  SourceBuiltIn,
+			"cursor":    SourceBuiltIn,
+			"browser":   SourceBuiltIn,
+			"borders":   SourceBuiltIn,
+			"color":     SourceBuiltIn,
+			"urls":      SourceBuiltIn,
+		},
+	}
+	l.apply(&settings.Defaults, settingsPath)
+	l.apply(&manifest.LaunchOptions, manifest.Path)
+	if flags != nil {
+		l.apply(flags, SourceFlag)
+	}
+	return l, nil
+}
+
+// apply overlays the values set in o, recording source for each.
+func (l *Launch) apply(o *LaunchOptions, source string) {
+	// A list of terminals implies how many to open, unless this layer
+	// also gives a count.
+	if o.TerminalCount != nil {
+		l.TerminalCount, l.Sources["terminals"] = *o.TerminalCount, source
+	} else if len(o.Terminals) > 0 {
+		l.TerminalCount, l.Sources["terminals"] = len(o.Terminals), source
+	}
+	if len(o.Terminals) > 0 {
+		l.Terminals, l.Sources["terminal"] = o.Terminals, source
+	}
+	if o.Cursor != nil {
+		l.Cursor, l.Sources["cursor"] = *o.Cursor, source
+	}
+	if o.Browser != nil {
+		l.Browser, l.Sources["browser"] = *o.Browser, source
+	}
+	if o.Borders != nil {
+		l.Borders, l.Sources["borders"] = *o.Borders, source
+	}
+	if o.Color != "" {
+		l.Color, l.Sources["color"] = o.Color, source
+	}
+	if len(o.URLs) > 0 {
+		l.URLs, l.Sources["urls"] = o.URLs, source
+	}
+}
+
+// parseLaunchOptions reads launch settings from a manifest or the
+// [defaults] table. Color is only accepted if allowColor is set, since a
+// colour shared by every project defeats the point. Keys in extra are
+// allowed but left for the caller to read.
+func parseLaunchOptions(t *toml.Table, allowColor bool, extra ...string) (LaunchOptions, error) {
+	keys := append([]string{"terminals", "terminal", "cursor", "browser", "borders", "urls"}, extra...)
+	if allowColor {
+		keys = append(keys, "color")
+	}
+	var o LaunchOptions
+	if err := t.CheckKeys(keys...); err != nil {
+		return o, err
+	}
+
+	if v := t.Get("terminals"); v != nil {
+		n, err := v.AsInt()
+		if err != nil {
+			return o, err
+		}
+		if n < 0 {
+			return o, toml.Errorf(v.Line, "terminals must not be negative")
+		}
+		count := int(n)
+		o.TerminalCount = &count
+	}
+
+	terminals, err := t.Tables("terminal")
+	if err != nil {
+		return o, err
+	}
+	for _, tt := range terminals {
+		if err := tt.CheckKeys("label", "command"); err != nil {
+			return o, err
+		}
+		var term Terminal
+		if term.Label, err = tt.String("label"); err != nil {
+			return o, err
+		}
+		if term.Command, err = tt.String("command"); err != nil {
+			return o, err
+		}
+		o.Terminals = append(o.Terminals, term)
+	}
+
+	flags := []struct {
+		key string
+		dst **bool
+	}{{"cursor", &o.Cursor}, {"browser", &o.Browser}, {"borders", &o.Borders}}
+	for _, f := range flags {
+		if v := t.Get(f.key); v != nil {
+			b, err := v.AsBool()
+			if err != nil {
+				return o, err
+			}
+			*f.dst = &b
+		}
+	}
+
+	if v := t.Get("color"); v != nil {
+		if o.Color, err = v.AsString(); err != nil {
+			return o, err
+		}
+		if color.ByName(o.Color) == nil {
+			return o, toml.Errorf(v.Line, "unknown color scheme %q (available: %v)", o.Color, color.Names())
+		}
+	}
+
+	if o.URLs, err = t.Strings("urls"); err != nil {
+		return o, err
+	}
+	return o, nil
+}
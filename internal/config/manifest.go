@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// ManifestFile is the name of the per-project manifest, read from the root
+// of the project directory.
+const ManifestFile = ".workspace.toml"
+
+// Manifest holds a project's launch settings from .workspace.toml.
+type Manifest struct {
+	// Path is the file the manifest was read from, or "" if the project
+	// has none.
+	Path string
+	// Inherit, if set to false, gives a subdirectory its own colour when
+	// the inherit setting would otherwise have it share an ancestor's.
+	Inherit *bool
+	LaunchOptions
+}
+
+// LoadManifest reads projectDir's manifest. A project without one yields an
+// empty manifest.
+func LoadManifest(projectDir string) (*Manifest, error) {
+	path := filepath.Join(projectDir, ManifestFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	m, err := parseManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.Path = path
+	return m, nil
+}
+
+func parseManifest(data []byte) (*Manifest, error) {
+	root, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	o, err := parseLaunchOptions(root, true, "inherit")
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{LaunchOptions: o}
+	if v := root.Get("inherit"); v != nil {
+		inherit, err := v.AsBool()
+		if err != nil {
+			return nil, err
+		}
+		m.Inherit = &inherit
+	}
+	return m, nil
+}
+
+// starterManifest is written by InitManifest. The values it sets match the
+// built-in defaults, so it launches the same workspace as running with no
+// flags.
+const starterManifest = `# workspace launch settings for this project.
+# Flags given on the command line override anything set here.
+
+# Colour for this project: a scheme name or a hex colour like "#ff8800".
+# color = "blue"
+
+# With inherit = true in config.toml, directories take the colour of their
+# nearest assigned ancestor. Set this to false to give this one its own.
+# inherit = false
+
+# Number of Ghostty windows. Defaults to the number of [[terminal]] tables.
+# terminals = 2
+
+# Also open Cursor, a themed Firefox window and JankyBorders?
+cursor  = true
+browser = false
+borders = false
+
+# Pages to open in the Firefox window.
+# urls = ["http://localhost:3000"]
+
+# One table per terminal window; command runs in the window's shell.
+[[terminal]]
+label = "Main"
+
+[[terminal]]
+label = "Server"
+# command = "make serve"
+`
+
+// InitManifest writes a starter manifest to projectDir and returns its
+// path. An existing manifest is only replaced if force is set.
+func InitManifest(projectDir string, force bool) (string, error) {
+	path := filepath.Join(projectDir, ManifestFile)
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return "", fmt.Errorf("%s already exists", path)
+		}
+	}
+	if err := os.WriteFile(path, []byte(starterManifest), 0o644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
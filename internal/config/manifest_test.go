@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestManifestPaletteColour checks that a manifest may name a scheme from
+// palettes.toml once the palettes are loaded, as workspace allow does
+// before parsing the manifest it is asked to trust.
+func TestManifestPaletteColour(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeEnv, home)
+	if err := os.WriteFile(filepath.Join(home, palettesFile), []byte("[[scheme]]\nname = \"navy\"\nbase = \"#3355aa\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	path := filepath.Join(project, ManifestFile)
+	if err := os.WriteFile(path, []byte("color = \"navy\"\n\n[[terminal]]\ncommand = \"make serve\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadManifest(project); err == nil {
+		t.Fatal("LoadManifest accepted navy before palettes.toml was loaded")
+	}
+	t.Cleanup(resetPalettes())
+	if err := LoadPalettes(); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(project)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+	if m.Color != "navy" {
+		t.Errorf("manifest colour = %q, want navy", m.Color)
+	}
+
+	if err := Allow(path); err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if ok, err := Trusted(path); err != nil || !ok {
+		t.Errorf("Trusted after Allow = %v, %v; want true", ok, err)
+	}
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// document is a config file decoded just far enough to read its version
+// and move fields around.
+type document map[string]json.RawMessage
+
+// A migration upgrades a document from version from to from+1. The version
+// field is updated by the caller.
+type migration struct {
+	from  int
+	desc  string
+	apply func(document) (document, error)
+}
+
+// schema describes the version history of one kind of config file.
+type schema struct {
+	name       string
+	migrations []migration
+}
+
+// current returns the version this build writes.
+func (s *schema) current() int { return len(s.migrations) }
+
+var assignmentsSchema = &schema{
+	name: "assignments",
+	migrations: []migration{
+		{
+			from: 0,
+			desc: "wrap the project map in a versioned envelope",
+			apply: func(d document) (document, error) {
+				projects, err := json.Marshal(d)
+				if err != nil {
+					return nil, err
+				}
+				return document{"assignments": projects}, nil
+			},
+		},
+	},
+}
+
+var sessionSchema = &schema{
+	name: "session",
+	migrations: []migration{
+		{
+			from:  0,
+			desc:  "add a version field",
+			apply: func(d document) (document, error) { return d, nil },
+		},
+	},
+}
+
+// ErrNewerVersion is returned for a file written by a newer build of
+// workspace. Such files are never rewritten, since that would drop
+// whatever the newer format added.
+var ErrNewerVersion = errors.New("written by a newer version of workspace; refusing to downgrade")
+
+// version returns the schema version of d. Files from before versioning
+// have no numeric version field and count as version 0.
+func version(d document) int {
+	var v int
+	if raw, ok := d["version"]; ok && json.Unmarshal(raw, &v) == nil {
+		return v
+	}
+	return 0
+}
+
+// upgrade decodes data and applies every migration needed to bring it to
+// the current version. It returns the upgraded document, the version it
+// started at and a description of each step applied.
+func (s *schema) upgrade(data []byte) (document, int, []string, error) {
+	var d document
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, 0, nil, err
+	}
+	if d == nil {
+		d = document{}
+	}
+
+	from := version(d)
+	if from > s.current() {
+		return nil, from, nil, fmt.Errorf("%s schema v%d (this build supports up to v%d): %w", s.name, from, s.current(), ErrNewerVersion)
+	}
+
+	var steps []string
+	for _, m := range s.migrations[from:] {
+		var err error
+		if d, err = m.apply(d); err != nil {
+			return nil, from, steps, fmt.Errorf("migrating %s from v%d: %w", s.name, m.from, err)
+		}
+		d["version"] = json.RawMessage(fmt.Sprint(m.from + 1))
+		steps = append(steps, fmt.Sprintf("v%d → v%d: %s", m.from, m.from+1, m.desc))
+	}
+	return d, from, steps, nil
+}
+
+// decode upgrades data in memory and unmarshals it into v.
+func (s *schema) decode(data []byte, v any) error {
+	d, _, _, err := s.upgrade(data)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Migration describes the upgrade of one file.
+type Migration struct {
+	Path   string
+	From   int
+	To     int
+	Steps  []string
+	Backup string // empty for a dry run
+}
+
+// schemaFile pairs a config file with the schema it follows.
+type schemaFile struct {
+	path   string
+	schema *schema
+}
+
+// Migrate upgrades the assignments file and every session file to the
+// current schema. Each upgraded file is first copied to
+// "<file>.v<old-version>.bak". With dryRun set nothing is written, and the
+// result reports what would change. Files already up to date are left out.
+//
+// A file written by a newer version of workspace makes Migrate fail with
+// ErrNewerVersion before anything is touched.
+func Migrate(dryRun bool) ([]Migration, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	files := []schemaFile{{path, assignmentsSchema}}
+	sessions, err := sessionFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range sessions {
+		files = append(files, schemaFile{p, sessionSchema})
+	}
+
+	// Work out every change first, so a newer file anywhere stops the
+	// whole migration rather than leaving it half done.
+	type pending struct {
+		Migration
+		data []byte
+		out  []byte
+	}
+	var todo []pending
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.path, err)
+		}
+		d, from, steps, err := f.schema.upgrade(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+		if len(steps) == 0 {
+			continue
+		}
+		out, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s: %w", f.path, err)
+		}
+		todo = append(todo, pending{
+			Migration: Migration{Path: f.path, From: from, To: f.schema.current(), Steps: steps},
+			data:      data,
+			out:       out,
+		})
+	}
+
+	var done []Migration
+	for _, p := range todo {
+		if !dryRun {
+			p.Backup = fmt.Sprintf("%s.v%d.bak", p.Path, p.From)
+			if err := writeBackup(p.Backup, p.data); err != nil {
+				return done, err
+			}
+			if err := writeFileAtomic(p.Path, p.out, 0o644); err != nil {
+				return done, err
+			}
+		}
+		done = append(done, p.Migration)
+	}
+	return done, nil
+}
+
+// writeBackup saves the original contents of a file before it is
+// migrated. An existing backup is kept, since it holds the oldest copy.
+func writeBackup(path string, data []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
+		return fmt.Errorf("backing up: %w", err)
+	}
+	return nil
+}
+
+// sessionFiles returns the paths of every session file.
+func sessionFiles() ([]string, error) {
+	dir, err := sessionsDirPath()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".json") {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	return paths, nil
+}
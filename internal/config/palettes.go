@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+const palettesFile = "palettes.toml"
+
+// LoadPalettes reads the user palette file, if there is one, and merges its
+// schemes into color.Palettes so that every lookup and auto-assignment sees
+// them. A missing file is not an error.
+func LoadPalettes() error {
+	path, err := configFile(palettesFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	f, err := color.ParsePalette(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	color.Merge(f)
+	return nil
+}
+
+// AddPalette appends a scheme to the user palette file, creating it if
+// needed. The resulting file is validated before it is written, so a
+// duplicate name or a scheme that fails the contrast check is rejected
+// without touching the file. A scheme identical to one already in the file
+// is left as it is, so two launches generating the same hue both succeed.
+// The file is locked while it is rewritten.
+func AddPalette(s *color.Scheme) (string, error) {
+	path, err := configFile(palettesFile)
+	if err != nil {
+		return "", err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	entry := color.FormatPaletteEntry(s)
+	if len(existing) > 0 {
+		if f, err := color.ParsePalette(existing); err == nil {
+			for i := range f.Schemes {
+				if f.Schemes[i].Name == s.Name && color.FormatPaletteEntry(&f.Schemes[i]) == entry {
+					return path, nil
+				}
+			}
+		}
+	}
+
+	data := existing
+	if len(data) > 0 {
+		if data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		data = append(data, '\n')
+	}
+	data = append(data, entry...)
+
+	if _, err := color.ParsePalette(data); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := writeFileAtomic(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const appName = "workspace-colours"
+
+// HomeEnv names the environment variable that relocates all of
+// workspace-colours' files — config and state — to a single directory.
+const HomeEnv = "WORKSPACE_COLOURS_HOME"
+
+// xdgDir returns $env if it holds an absolute path (the XDG spec says
+// relative ones are to be ignored), else fallback under the home directory.
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	return filepath.Join(home, fallback), nil
+}
+
+// ConfigDir returns the directory holding the assignments, palettes and
+// settings: $WORKSPACE_COLOURS_HOME if set, else
+// $XDG_CONFIG_HOME/workspace-colours, else ~/.config/workspace-colours.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	base, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// StateDir returns the directory holding session files:
+// $WORKSPACE_COLOURS_HOME if set, else $XDG_STATE_HOME/workspace-colours,
+// else ~/.local/state/workspace-colours.
+func StateDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	base, err := xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// legacyDir is where every file lived before XDG directories were honoured.
+func legacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", appName), nil
+}
+
+// Relocate moves data from ~/.config/workspace-colours to the directories
+// now in use, and returns a line describing each move:
+//
+//   - Session files move to the state directory.
+//   - If $XDG_CONFIG_HOME points elsewhere, the config files are copied to
+//     it the first time it is used. The originals are left in place, so
+//     pointing XDG_CONFIG_HOME somewhere temporarily loses nothing.
+//
+// Nothing is done when $WORKSPACE_COLOURS_HOME is set, since that asks for
+// a separate root (a hermetic test directory, say) rather than a new home
+// for existing data.
+func Relocate() ([]string, error) {
+	if os.Getenv(HomeEnv) != "" {
+		return nil, nil
+	}
+	legacy, err := legacyDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(legacy); os.IsNotExist(err) {
+		return nil, nil
+	}
+	// Two launches starting at once must not both try to move the data.
+	unlock, err := lockFile(filepath.Join(legacy, "relocate"))
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var moved []string
+	cfg, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	if cfg != legacy && !exists(cfg) {
+		if err := copyDir(legacy, cfg, sessionsDir); err != nil {
+			return moved, fmt.Errorf("copying %s to %s: %w", legacy, cfg, err)
+		}
+		moved = append(moved, fmt.Sprintf("Copied config from %s to %s", legacy, cfg))
+	}
+
+	oldSessions := filepath.Join(legacy, sessionsDir)
+	newSessions, err := sessionsDirPath()
+	if err != nil {
+		return moved, err
+	}
+	if oldSessions != newSessions && exists(oldSessions) {
+		if err := moveDir(oldSessions, newSessions); err != nil {
+			return moved, fmt.Errorf("moving %s to %s: %w", oldSessions, newSessions, err)
+		}
+		moved = append(moved, fmt.Sprintf("Moved sessions from %s to %s", oldSessions, newSessions))
+	}
+	return moved, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// moveDir moves the files in src into dst, keeping any file dst already
+// has, then removes src.
+func moveDir(src, dst string) error {
+	if !exists(dst) {
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.Rename(src, dst); err == nil {
+			return nil
+		}
+		// Most likely a different filesystem; fall back to copying.
+	}
+	if err := copyDir(src, dst, ""); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyDir copies the regular files directly in src to dst, skipping the
+// entry named skip, lock files and any file dst already has.
+func copyDir(src, dst, skip string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Name() == skip || !e.Type().IsRegular() || strings.HasSuffix(e.Name(), ".lock") {
+			continue
+		}
+		to := filepath.Join(dst, e.Name())
+		if exists(to) {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, e.Name()), to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(dst, data, 0o644)
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// Policy constrains automatic assignment. It comes from the [policy] table
+// in config.toml. Only --color may give a project a reserved colour: a team
+// colour that is reserved is ignored, as a rule using one is rejected.
+// Caps and the preferred order don't apply to colours chosen explicitly.
+type Policy struct {
+	// Reserved schemes are never handed out automatically.
+	Reserved []string
+	// Prefer lists schemes to hand out first, in order.
+	Prefer []string
+	// Caps limit how many projects may share a scheme, from the
+	// [policy.caps] table.
+	Caps map[string]int
+}
+
+// IsReserved reports whether the scheme named name is reserved.
+func (p *Policy) IsReserved(name string) bool {
+	for _, r := range p.Reserved {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// full reports whether the scheme named name has as many projects as its
+// cap allows, given how many projects use each scheme.
+func (p *Policy) full(name string, counts map[string]int) bool {
+	max, ok := p.Caps[name]
+	return ok && counts[name] >= max
+}
+
+// allows reports whether automatic assignment may hand out the scheme
+// named name.
+func (p *Policy) allows(name string, counts map[string]int) bool {
+	return !p.IsReserved(name) && !p.full(name, counts)
+}
+
+// schemeCounts returns how many projects use each scheme.
+func schemeCounts(assignments Assignments) map[string]int {
+	counts := make(map[string]int)
+	for _, a := range assignments {
+		counts[a.Scheme]++
+	}
+	return counts
+}
+
+// explain adds notes to d on what the policy kept from being chosen.
+func (p *Policy) explain(d *Decision, assignments Assignments) {
+	if len(p.Reserved) > 0 {
+		d.note("reserved, so only given with --color: %s", strings.Join(p.Reserved, ", "))
+	}
+	counts := schemeCounts(assignments)
+	var full []string
+	for name, max := range p.Caps {
+		if p.full(name, counts) {
+			full = append(full, fmt.Sprintf("%s (%d of %d)", name, counts[name], max))
+		}
+	}
+	if len(full) > 0 {
+		sort.Strings(full)
+		d.note("at their cap: %s", strings.Join(full, ", "))
+	}
+	var taken []string
+	for _, name := range p.Prefer {
+		if d.Scheme != nil && name == d.Scheme.Name {
+			break
+		}
+		if counts[name] > 0 && !p.full(name, counts) {
+			taken = append(taken, name)
+		}
+	}
+	if len(taken) > 0 {
+		d.note("preferred but already assigned: %s", strings.Join(taken, ", "))
+	}
+}
+
+func parsePolicy(root *toml.Table) (Policy, error) {
+	var p Policy
+	t, err := root.Table("policy")
+	if err != nil || t == nil {
+		return p, err
+	}
+	if err := t.CheckKeys("reserved", "prefer", "caps"); err != nil {
+		return p, err
+	}
+	if p.Reserved, err = schemeNames(t, "reserved"); err != nil {
+		return p, err
+	}
+	if p.Prefer, err = schemeNames(t, "prefer"); err != nil {
+		return p, err
+	}
+	for _, name := range p.Prefer {
+		if p.IsReserved(name) {
+			return p, toml.Errorf(t.Get("prefer").Line, "%s is both reserved and preferred", name)
+		}
+	}
+
+	caps, err := t.Table("caps")
+	if err != nil || caps == nil {
+		return p, err
+	}
+	p.Caps = make(map[string]int)
+	for _, name := range caps.Keys() {
+		v := caps.Get(name)
+		n, err := v.AsInt()
+		if err != nil {
+			return p, err
+		}
+		if n < 1 {
+			return p, toml.Errorf(v.Line, "cap for %s must be at least 1", name)
+		}
+		s := color.ByName(name)
+		if s == nil {
+			return p, toml.Errorf(v.Line, "unknown color scheme %q (available: %v)", name, color.Names())
+		}
+		p.Caps[s.Name] = int(n)
+	}
+	return p, nil
+}
+
+// schemeNames reads a list of scheme names, checking each exists.
+func schemeNames(t *toml.Table, key string) ([]string, error) {
+	names, err := t.Strings(key)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		s := color.ByName(name)
+		if s == nil {
+			return nil, toml.Errorf(t.Get(key).Line, "unknown color scheme %q (available: %v)", name, color.Names())
+		}
+		names[i] = s.Name
+	}
+	return names, nil
+}
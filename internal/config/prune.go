@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stale is an assignment that Prune removes, and why.
+type Stale struct {
+	Key        string
+	Assignment Assignment
+	Reason     string
+}
+
+// PruneResult lists what Prune removed, or would remove on a dry run.
+type PruneResult struct {
+	Assignments []Stale
+	// Sessions are the project directories whose session files go.
+	Sessions []string
+}
+
+// Prune removes assignments whose directories no longer exist and, if
+// maxAge is non-zero, those not used within maxAge, along with the session
+// files of pruned or deleted projects. With dryRun set nothing is changed.
+func Prune(maxAge time.Duration, dryRun bool) (*PruneResult, error) {
+	res := &PruneResult{}
+	err := update(func(assignments Assignments) error {
+		res.Assignments = findStale(assignments, maxAge, time.Now(), "")
+		if dryRun || len(res.Assignments) == 0 {
+			return errUnchanged
+		}
+		for _, s := range res.Assignments {
+			delete(assignments, s.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Sessions, err = pruneSessions(res.Assignments, dryRun); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// findStale returns the assignments that should be pruned, sorted by key.
+// The assignment under keep is never included, so a project being opened
+// can't lose its colour.
+func findStale(assignments Assignments, maxAge time.Duration, now time.Time, keep string) []Stale {
+	var stale []Stale
+	for key, a := range assignments {
+		if key == keep {
+			continue
+		}
+		if reason := staleReason(key, a, maxAge, now); reason != "" {
+			stale = append(stale, Stale{Key: key, Assignment: a, Reason: reason})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Key < stale[j].Key })
+	return stale
+}
+
+// staleReason says why an assignment should be pruned, or returns "" if
+// it should stay.
+func staleReason(key string, a Assignment, maxAge time.Duration, now time.Time) string {
+	dirs := a.Paths
+	if len(dirs) == 0 && !strings.HasPrefix(key, remotePrefix) && !strings.HasPrefix(key, rootCommitPrefix) {
+		dirs = []string{key}
+	}
+	// A git identity nobody has opened here (imported from another
+	// machine, say) can't be judged by its directories.
+	if len(dirs) > 0 && allMissing(dirs) {
+		if len(dirs) == 1 {
+			return "directory no longer exists"
+		}
+		return "directories no longer exist"
+	}
+	if maxAge > 0 {
+		if used := a.LastUsed(); now.Sub(used) > maxAge {
+			return fmt.Sprintf("not used since %s", used.Format("2006-01-02"))
+		}
+	}
+	return ""
+}
+
+// LastUsed returns when a project was last opened, or for one not opened
+// since opens were recorded, when it was given its colour.
+func (a Assignment) LastUsed() time.Time {
+	if a.LastOpenedAt.After(a.AssignedAt) {
+		return a.LastOpenedAt
+	}
+	return a.AssignedAt
+}
+
+// released reports whether a has gone unused for longer than ttl, so its
+// colour is free for other projects. A zero ttl releases nothing.
+func released(a Assignment, ttl time.Duration, now time.Time) bool {
+	return ttl > 0 && now.Sub(a.LastUsed()) > ttl
+}
+
+// Released reports whether a's colour has been released under the
+// release_after setting.
+func (s *Settings) Released(a Assignment) bool {
+	return released(a, s.ReleaseAfter, time.Now())
+}
+
+// liveAssignments returns the assignments that still hold their colour:
+// all but the one under skip and those released after ttl.
+func liveAssignments(assignments Assignments, skip string, ttl time.Duration, now time.Time) Assignments {
+	live := make(Assignments, len(assignments))
+	for key, a := range assignments {
+		if key != skip && !released(a, ttl, now) {
+			live[key] = a
+		}
+	}
+	return live
+}
+
+// allMissing reports whether none of dirs exist. A directory that can't be
+// checked (on an unmounted drive, say) counts as present.
+func allMissing(dirs []string) bool {
+	for _, d := range dirs {
+		if _, err := os.Stat(d); !os.IsNotExist(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneSessions removes the session files of pruned projects and of
+// projects whose directory is gone, unless a process they track is still
+// running. It returns the project directories whose sessions went.
+func pruneSessions(pruned []Stale, dryRun bool) ([]string, error) {
+	gone := make(map[string]bool)
+	for _, s := range pruned {
+		gone[s.Key] = true
+		for _, p := range s.Assignment.Paths {
+			gone[p] = true
+		}
+	}
+
+	sessions, err := ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, s := range sessions {
+		if !gone[s.ProjectDir] && !allMissing([]string{s.ProjectDir}) {
+			continue
+		}
+		if sessionAlive(s) {
+			continue
+		}
+		if !dryRun {
+			if err := DeleteSession(s.ProjectDir); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, s.ProjectDir)
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// sessionAlive reports whether any process in s is still running.
+func sessionAlive(s *Session) bool {
+	for _, p := range s.Processes {
+		if IsProcessAlive(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatAge formats a duration as ParseAge would accept it, in whole days
+// where it can be.
+func formatAge(d time.Duration) string {
+	if day := 24 * time.Hour; d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
+
+// ParseAge parses a prune age such as "90d", "12w" or "720h". Days and
+// weeks are added to the units time.ParseDuration accepts.
+func ParseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid age %q (want e.g. 90d, 12w or 720h)", s)
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (want e.g. 90d, 12w or 720h)", s)
+	}
+	return d, nil
+}
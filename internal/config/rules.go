@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// Rule gives every new project whose directory matches a pattern the same
+// colour. Rules come from [[rule]] tables in config.toml and are tried in
+// order.
+type Rule struct {
+	// Pattern is the glob or regular expression as written, for display.
+	Pattern string
+	Color   string
+	// Line is where the rule starts in config.toml.
+	Line int
+
+	re *regexp.Regexp
+}
+
+// Match reports whether the rule applies to the absolute directory dir.
+func (r *Rule) Match(dir string) bool {
+	return r.re.MatchString(filepath.ToSlash(dir))
+}
+
+func (r *Rule) String() string {
+	return fmt.Sprintf("%s → %s", r.Pattern, r.Color)
+}
+
+// matchRule returns the first rule that matches dir, and its 1-based
+// position, or nil.
+func matchRule(rules []Rule, dir string) (*Rule, int) {
+	for i := range rules {
+		if rules[i].Match(dir) {
+			return &rules[i], i + 1
+		}
+	}
+	return nil, 0
+}
+
+func parseRules(root *toml.Table) ([]Rule, error) {
+	tables, err := root.Tables("rule")
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	for _, t := range tables {
+		if err := t.CheckKeys("path", "regex", "color"); err != nil {
+			return nil, err
+		}
+		path, err := t.String("path")
+		if err != nil {
+			return nil, err
+		}
+		expr, err := t.String("regex")
+		if err != nil {
+			return nil, err
+		}
+		r := Rule{Line: t.Line}
+		if r.Color, err = t.String("color"); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case path != "" && expr != "":
+			return nil, toml.Errorf(t.Line, "rule has both path and regex; use one")
+		case path != "":
+			r.Pattern = path
+			if r.re, err = globRegexp(path); err != nil {
+				return nil, toml.Errorf(t.Line, "%v", err)
+			}
+		case expr != "":
+			r.Pattern = "regex " + expr
+			if r.re, err = regexp.Compile(expr); err != nil {
+				return nil, toml.Errorf(t.Line, "invalid regex: %v", err)
+			}
+		default:
+			return nil, toml.Errorf(t.Line, "rule needs a path or regex")
+		}
+
+		if r.Color == "" {
+			return nil, toml.Errorf(t.Line, "rule needs a color")
+		}
+		if color.ByName(r.Color) == nil {
+			return nil, toml.Errorf(t.Line, "unknown color scheme %q (available: %v)", r.Color, color.Names())
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// globRegexp compiles a path glob into a regular expression matching whole
+// paths. "*" and "?" stay within one path segment, "**" spans any number of
+// them, and a leading "~/" is the home directory. "dir/**" also matches dir
+// itself.
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	if rest, ok := strings.CutPrefix(glob, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("finding home directory: %w", err)
+		}
+		glob = filepath.ToSlash(home) + "/" + rest
+	}
+	if !strings.HasPrefix(glob, "/") {
+		return nil, fmt.Errorf("path %q must be absolute or start with ~/", glob)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "/**"):
+			b.WriteString("(/.*)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in %q", glob)
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("/?$")
+	return regexp.Compile(b.String())
+}
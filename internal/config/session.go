@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"syscall"
 	"time"
 )
@@ -23,6 +22,7 @@ type TrackedProcess struct {
 
 // Session records all processes launched for a workspace.
 type Session struct {
+	Version    int               `json:"version"`
 	ProjectDir string            `json:"project_dir"`
 	Scheme     string            `json:"scheme"`
 	Processes  []TrackedProcess  `json:"processes"`
@@ -32,12 +32,21 @@ type Session struct {
 // sessionPath returns the file path for a project's session file.
 // Uses a SHA256 hash of the absolute path to avoid filesystem issues.
 func sessionPath(projectDir string) (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := sessionsDirPath()
 	if err != nil {
 		return "", err
 	}
 	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(projectDir)))[:16]
-	return filepath.Join(home, configDir, sessionsDir, hash+".json"), nil
+	return filepath.Join(dir, hash+".json"), nil
+}
+
+// sessionsDirPath returns the directory holding the session files.
+func sessionsDirPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionsDir), nil
 }
 
 // LoadSession reads the session file for a project. Returns nil if no session exists.
@@ -61,28 +70,26 @@ func LoadSession(projectDir string) (*Session, error) {
 	}
 
 	var s Session
-	if err := json.Unmarshal(data, &s); err != nil {
+	if err := sessionSchema.decode(data, &s); err != nil {
 		return nil, fmt.Errorf("parsing session: %w", err)
 	}
 	return &s, nil
 }
 
-// SaveSession writes the session to disk.
+// SaveSession writes the session to disk atomically, so a concurrent
+// ListSessions never sees a half-written file.
 func SaveSession(s *Session) error {
 	path, err := sessionPath(s.ProjectDir)
 	if err != nil {
 		return err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("creating sessions directory: %w", err)
-	}
-
+	s.Version = sessionSchema.current()
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0o644)
+	return writeFileAtomic(path, data, 0o644)
 }
 
 // DeleteSession removes the session file for a project.
@@ -102,33 +109,22 @@ func DeleteSession(projectDir string) error {
 	return nil
 }
 
-// ListSessions returns all active sessions.
+// ListSessions returns all active sessions. Files that can't be read, or
+// that were written by a newer version of workspace, are skipped.
 func ListSessions() ([]*Session, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
-	}
-
-	dir := filepath.Join(home, configDir, sessionsDir)
-	entries, err := os.ReadDir(dir)
-	if os.IsNotExist(err) {
-		return nil, nil
-	}
+	paths, err := sessionFiles()
 	if err != nil {
 		return nil, err
 	}
 
 	var sessions []*Session
-	for _, e := range entries {
-		if !strings.HasSuffix(e.Name(), ".json") {
-			continue
-		}
-		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
 		if err != nil {
 			continue
 		}
 		var s Session
-		if err := json.Unmarshal(data, &s); err != nil {
+		if err := sessionSchema.decode(data, &s); err != nil {
 			continue
 		}
 		sessions = append(sessions, &s)
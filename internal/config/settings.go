@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+const settingsFile = "config.toml"
+
+// Settings holds global preferences from config.toml in the config
+// directory. Zero values mean "use the built-in default".
+type Settings struct {
+	// Appearance selects the light or dark variant of every scheme, unless
+	// a project sets its own.
+	Appearance color.Appearance
+	// CVD is a colour-vision deficiency that auto-assignment keeps colours
+	// distinguishable under.
+	CVD color.Deficiency
+	// ExtendPalette generates a new hue instead of reusing a colour once
+	// every scheme has been assigned.
+	ExtendPalette bool
+	// Identity selects what assignments are keyed by. Empty means
+	// IdentityPath.
+	Identity Identity
+	// Inherit gives a directory the colour of the project it is inside:
+	// its nearest assigned ancestor or its git repository (see locate).
+	Inherit bool
+	// AutoPrune makes GetOrAssign drop stale assignments, as Prune does.
+	AutoPrune bool
+	// PruneAfter is how long an assignment may go unused before it is
+	// pruned. Zero means age alone never prunes one.
+	PruneAfter time.Duration
+	// ReleaseAfter is how long an assignment may go unused before its
+	// colour is free to hand to another project. The assignment itself
+	// stays, and gets its colour back if nobody has taken it. Zero means
+	// colours are never released.
+	ReleaseAfter time.Duration
+	// Rules give new projects under matching paths a fixed colour, from
+	// [[rule]] tables. The first match wins.
+	Rules []Rule
+	// Policy constrains which schemes automatic assignment hands out.
+	Policy Policy
+	// Families group related projects under one hue, from [[family]]
+	// tables. Members get shades of it.
+	Families []Family
+	// Defaults are launch settings for every project, from the [defaults]
+	// table. Project manifests and flags override them.
+	Defaults LaunchOptions
+}
+
+// LoadSettings reads the global settings file. A missing file yields empty
+// settings.
+func LoadSettings() (*Settings, error) {
+	path, err := configFile(settingsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	s, err := parseSettings(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// SettingsFile returns the path of the global settings file and its
+// contents, or nil contents if it doesn't exist.
+func SettingsFile() (string, []byte, error) {
+	path, err := configFile(settingsFile)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return path, nil, nil
+	}
+	if err != nil {
+		return path, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return path, data, nil
+}
+
+func parseSettings(data []byte) (*Settings, error) {
+	root, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := root.CheckKeys("appearance", "cvd", "extend_palette", "identity", "inherit", "auto_prune", "prune_after", "release_after", "rule", "family", "policy", "defaults"); err != nil {
+		return nil, err
+	}
+
+	s := &Settings{}
+	if v := root.Get("appearance"); v != nil {
+		raw, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		if s.Appearance, err = color.ParseAppearance(raw); err != nil {
+			return nil, toml.Errorf(v.Line, "%v", err)
+		}
+	}
+	if v := root.Get("cvd"); v != nil {
+		raw, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		if s.CVD, err = color.ParseDeficiency(raw); err != nil {
+			return nil, toml.Errorf(v.Line, "%v", err)
+		}
+	}
+	if v := root.Get("extend_palette"); v != nil {
+		if s.ExtendPalette, err = v.AsBool(); err != nil {
+			return nil, err
+		}
+	}
+	if v := root.Get("identity"); v != nil {
+		raw, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		if s.Identity, err = ParseIdentity(raw); err != nil {
+			return nil, toml.Errorf(v.Line, "%v", err)
+		}
+	}
+	if v := root.Get("inherit"); v != nil {
+		if s.Inherit, err = v.AsBool(); err != nil {
+			return nil, err
+		}
+	}
+	if v := root.Get("auto_prune"); v != nil {
+		if s.AutoPrune, err = v.AsBool(); err != nil {
+			return nil, err
+		}
+	}
+	if v := root.Get("prune_after"); v != nil {
+		raw, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		if s.PruneAfter, err = ParseAge(raw); err != nil {
+			return nil, toml.Errorf(v.Line, "%v", err)
+		}
+	}
+	if v := root.Get("release_after"); v != nil {
+		raw, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		if s.ReleaseAfter, err = ParseAge(raw); err != nil {
+			return nil, toml.Errorf(v.Line, "%v", err)
+		}
+	}
+	if s.Policy, err = parsePolicy(root); err != nil {
+		return nil, err
+	}
+	if s.Rules, err = parseRules(root); err != nil {
+		return nil, err
+	}
+	for _, r := range s.Rules {
+		if s.Policy.IsReserved(color.ByName(r.Color).Name) {
+			return nil, toml.Errorf(r.Line, "rule uses %s, which [policy] reserves for --color", r.Color)
+		}
+	}
+	if s.Families, err = parseFamilies(root); err != nil {
+		return nil, err
+	}
+	defaults, err := root.Table("defaults")
+	if err != nil {
+		return nil, err
+	}
+	if defaults != nil {
+		if s.Defaults, err = parseLaunchOptions(defaults, false); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// Export returns the assignments as a TOML file that Import reads, so a
+// team can share a baseline mapping. A git repository opened here is
+// written under its remote (see IdentityRemote) where it has one, since
+// its path means nothing on another machine, and other paths under the
+// home directory are written as ~/... It also returns the number of
+// projects written.
+func Export() ([]byte, int, error) {
+	assignments, err := Load()
+	if err != nil {
+		return nil, 0, err
+	}
+	keys := make([]string, 0, len(assignments))
+	for key := range assignments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	colors := make(map[string]string)
+	for _, key := range keys {
+		portable := portableKey(key, assignments[key])
+		// A remote identity of its own beats a path that maps to it.
+		if _, dup := colors[portable]; !dup || portable == key {
+			colors[portable] = assignments[key].Scheme
+		}
+	}
+	out := make([]string, 0, len(colors))
+	for key := range colors {
+		out = append(out, key)
+	}
+	sort.Strings(out)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Colour assignments exported by workspace on %s.\n", time.Now().Format("2006-01-02"))
+	b.WriteString("# Import with: workspace assignments import <file>\n\n[assignments]\n")
+	for _, key := range out {
+		fmt.Fprintf(&b, "%s = %s\n", toml.Quote(key), toml.Quote(colors[key]))
+	}
+	return []byte(b.String()), len(out), nil
+}
+
+// portableKey returns the key a project is exported under: its remote
+// identity if it is a git repository with a remote, else key, with the
+// home directory abbreviated to ~.
+func portableKey(key string, a Assignment) string {
+	if strings.HasPrefix(key, remotePrefix) {
+		return key
+	}
+	dirs := a.Paths
+	if !strings.HasPrefix(key, rootCommitPrefix) {
+		dirs = append([]string{key}, dirs...)
+	}
+	for _, d := range dirs {
+		if k := projectKey(d, IdentityRemote); strings.HasPrefix(k, remotePrefix) {
+			return k
+		}
+	}
+	return abbrevHome(key)
+}
+
+// abbrevHome rewrites a path under the home directory as ~/..., the form
+// parseImport expands. Other keys are returned unchanged.
+func abbrevHome(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	// Keys are stored with symlinks resolved, so try the resolved home too.
+	homes := []string{home}
+	if real, err := filepath.EvalSymlinks(home); err == nil && real != home {
+		homes = append(homes, real)
+	}
+	for _, h := range homes {
+		if rel, err := filepath.Rel(h, path); err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return "~/" + filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// ImportResult lists what Import did with each project in the file.
+type ImportResult struct {
+	Added, Replaced []string
+	// Kept are projects that already had a different colour, left alone
+	// because overwrite was not set.
+	Kept []string
+	// Unknown are projects whose colour isn't available here, such as
+	// one from a palette this machine doesn't have.
+	Unknown []string
+	// NeedIdentity counts projects keyed by git identity, which only
+	// apply once the identity setting matches.
+	NeedIdentity int
+}
+
+// Import reads assignments written by Export and merges them into this
+// machine's. Projects that already have a colour keep it unless overwrite
+// is set, so importing a team's baseline never undoes personal choices.
+func Import(data []byte, overwrite bool) (*ImportResult, error) {
+	imported, err := parseImport(data)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(imported))
+	for key := range imported {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	gitKeyed := settings.Identity == IdentityRemote || settings.Identity == IdentityRootCommit
+	res := &ImportResult{}
+	err = update(func(assignments Assignments) error {
+		now := time.Now()
+		for _, key := range keys {
+			scheme := color.ByName(imported[key])
+			if scheme == nil {
+				res.Unknown = append(res.Unknown, key)
+				continue
+			}
+			if strings.HasPrefix(key, remotePrefix) && settings.Identity != IdentityRemote ||
+				strings.HasPrefix(key, rootCommitPrefix) && !gitKeyed {
+				res.NeedIdentity++
+			}
+			a, ok := assignments[key]
+			switch {
+			case ok && a.Scheme == scheme.Name:
+				continue
+			case ok && !overwrite:
+				res.Kept = append(res.Kept, key)
+				continue
+			case ok:
+				res.Replaced = append(res.Replaced, key)
+			default:
+				res.Added = append(res.Added, key)
+			}
+			a.Scheme, a.AssignedAt = scheme.Name, now
+			assignments[key] = a
+		}
+		if len(res.Added)+len(res.Replaced) == 0 {
+			return errUnchanged
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// parseImport reads an exported file's [assignments] table. Path keys may
+// start with ~/ for the importer's home directory, and have symlinks
+// resolved as launches do, so they match the keys launches store under.
+func parseImport(data []byte) (map[string]string, error) {
+	root, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := root.CheckKeys("assignments"); err != nil {
+		return nil, err
+	}
+	t, err := root.Table("assignments")
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("no [assignments] table")
+	}
+
+	imported := make(map[string]string)
+	for _, key := range t.Keys() {
+		v := t.Get(key)
+		name, err := v.AsString()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(key, remotePrefix) && !strings.HasPrefix(key, rootCommitPrefix) {
+			if key, err = expandDir(key); err != nil {
+				return nil, toml.Errorf(v.Line, "%v", err)
+			}
+			if key, _, err = resolveDir(key); err != nil {
+				return nil, toml.Errorf(v.Line, "%v", err)
+			}
+		}
+		imported[key] = name
+	}
+	return imported, nil
+}
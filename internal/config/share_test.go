@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestImportResolvesSymlinks checks that a project imported by a path
+// through a symlink gets the same key a launch there would use, so the
+// launch finds the imported colour.
+func TestImportResolvesSymlinks(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	real, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("can't create symlinks: %v", err)
+	}
+
+	res, err := Import([]byte("[assignments]\n\""+link+"\" = \"teal\"\n"), false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(res.Added) != 1 || res.Added[0] != real {
+		t.Errorf("added %q, want [%s]", res.Added, real)
+	}
+
+	s, err := GetOrAssign(link, "")
+	if err != nil {
+		t.Fatalf("GetOrAssign: %v", err)
+	}
+	if s.Name != "teal" {
+		t.Errorf("launching through the symlink gave %s, want the imported teal", s.Name)
+	}
+	assignments, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assignments) != 1 {
+		t.Errorf("%d assignments, want just the imported one: %v", len(assignments), assignments)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// trustFile records the manifests allowed to run commands, in the state
+// directory, as a map from manifest path to the hash of its contents.
+const trustFile = "trusted.json"
+
+// trustPath returns the full path to the trust file.
+func trustPath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, trustFile), nil
+}
+
+// trustKey is the key a manifest is trusted under: its path with symlinks
+// resolved, so a checkout is trusted once however it is reached.
+func trustKey(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return filepath.Clean(path)
+}
+
+// manifestHash returns the hash a manifest is trusted under.
+func manifestHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
+
+func loadTrust(path string) (map[string]string, error) {
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return trusted, nil
+}
+
+// Trusted reports whether the manifest at path may run its commands: it
+// has been allowed (see Allow) and not changed since. A manifest comes
+// with the repository, so one that is new or edited, by a pull say, has to
+// be looked over again.
+func Trusted(path string) (bool, error) {
+	tp, err := trustPath()
+	if err != nil {
+		return false, err
+	}
+	trusted, err := loadTrust(tp)
+	if err != nil {
+		return false, err
+	}
+	want, ok := trusted[trustKey(path)]
+	if !ok {
+		return false, nil
+	}
+	hash, err := manifestHash(path)
+	if err != nil {
+		return false, err
+	}
+	return hash == want, nil
+}
+
+// Allow trusts the manifest at path, as it is now, to run commands.
+func Allow(path string) error {
+	hash, err := manifestHash(path)
+	if err != nil {
+		return err
+	}
+	key := trustKey(path)
+	return updateTrust(func(trusted map[string]string) bool {
+		if trusted[key] == hash {
+			return false
+		}
+		trusted[key] = hash
+		return true
+	})
+}
+
+// Deny withdraws trust from the manifest at path. It reports whether the
+// manifest had been allowed.
+func Deny(path string) (bool, error) {
+	key := trustKey(path)
+	var found bool
+	err := updateTrust(func(trusted map[string]string) bool {
+		_, found = trusted[key]
+		delete(trusted, key)
+		return found
+	})
+	return found, err
+}
+
+// updateTrust loads the trust file under its lock, calls fn and writes the
+// result back if fn reports a change.
+func updateTrust(fn func(map[string]string) bool) error {
+	path, err := trustPath()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	trusted, err := loadTrust(path)
+	if err != nil {
+		return err
+	}
+	if !fn(trusted) {
+		return nil
+	}
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,50 @@
+// Package desktop reads the desktop's dark/light preference so workspaces
+// can follow it.
+//
+// On Linux the preference comes from the freedesktop settings portal
+// (org.freedesktop.appearance color-scheme) on the session D-Bus, queried
+// with gdbus or dbus-send. On macOS it comes from the global
+// AppleInterfaceStyle default.
+//
+// The Linux tests stand a helper process in for gdbus and dbus-send, which
+// replies as the portal would. That covers the commands run and the
+// replies and signals parsed, but not a real session bus or portal.
+package desktop
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// Portal values for org.freedesktop.appearance color-scheme.
+const (
+	portalNoPreference = 0
+	portalPreferDark   = 1
+	portalPreferLight  = 2
+)
+
+// uint32Pattern matches the value in both gdbus ("<<uint32 1>>") and
+// dbus-send ("variant variant uint32 1") replies.
+var uint32Pattern = regexp.MustCompile(`uint32 (\d+)`)
+
+// parsePortalReply extracts the appearance from a portal Read reply. "No
+// preference" is treated as dark, matching the built-in schemes.
+func parsePortalReply(out string) (color.Appearance, bool) {
+	m := uint32Pattern.FindStringSubmatch(out)
+	if m == nil {
+		return "", false
+	}
+	v, err := strconv.Atoi(m[1])
+	if err != nil {
+		return "", false
+	}
+	switch v {
+	case portalPreferLight:
+		return color.Light, true
+	case portalPreferDark, portalNoPreference:
+		return color.Dark, true
+	}
+	return "", false
+}
@@ -0,0 +1,47 @@
+package desktop
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// pollInterval is how often Watch re-reads the preference. macOS has no
+// command-line equivalent of the D-Bus change signal.
+const pollInterval = 2 * time.Second
+
+// Appearance returns the desktop's current dark/light preference. macOS only
+// sets AppleInterfaceStyle in dark mode, so a failed read means light.
+func Appearance() (color.Appearance, error) {
+	out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+	if err != nil {
+		return color.Light, nil
+	}
+	if strings.EqualFold(strings.TrimSpace(string(out)), "dark") {
+		return color.Dark, nil
+	}
+	return color.Light, nil
+}
+
+// Watch calls fn with the new appearance every time the desktop preference
+// changes, until ctx is cancelled.
+func Watch(ctx context.Context, fn func(color.Appearance)) error {
+	last, _ := Appearance()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a, _ := Appearance()
+			if a != last {
+				last = a
+				fn(a)
+			}
+		}
+	}
+}
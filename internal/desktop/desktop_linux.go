@@ -0,0 +1,96 @@
+package desktop
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+const (
+	portalDest      = "org.freedesktop.portal.Desktop"
+	portalPath      = "/org/freedesktop/portal/desktop"
+	portalNamespace = "org.freedesktop.appearance"
+	portalKey       = "color-scheme"
+)
+
+// lookPath and command find and run gdbus and dbus-send. Tests replace them
+// to stand in for the portal without a session bus.
+var (
+	lookPath = exec.LookPath
+	command  = exec.CommandContext
+)
+
+// Appearance returns the desktop's current dark/light preference.
+func Appearance() (color.Appearance, error) {
+	var out []byte
+	var err error
+	if gdbus, lookErr := lookPath("gdbus"); lookErr == nil {
+		out, err = command(context.Background(), gdbus, "call", "--session",
+			"--dest", portalDest,
+			"--object-path", portalPath,
+			"--method", "org.freedesktop.portal.Settings.Read",
+			portalNamespace, portalKey,
+		).Output()
+	} else if dbusSend, lookErr := lookPath("dbus-send"); lookErr == nil {
+		out, err = command(context.Background(), dbusSend, "--session", "--print-reply=literal",
+			"--dest="+portalDest, portalPath,
+			"org.freedesktop.portal.Settings.Read",
+			"string:"+portalNamespace, "string:"+portalKey,
+		).Output()
+	} else {
+		return "", fmt.Errorf("neither gdbus nor dbus-send found in PATH")
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s %s from the settings portal: %w", portalNamespace, portalKey, err)
+	}
+
+	a, ok := parsePortalReply(string(out))
+	if !ok {
+		return "", fmt.Errorf("unexpected settings portal reply: %s", strings.TrimSpace(string(out)))
+	}
+	return a, nil
+}
+
+// Watch calls fn with the new appearance every time the desktop preference
+// changes, until ctx is cancelled. It listens for the portal's
+// SettingChanged signal via gdbus monitor.
+func Watch(ctx context.Context, fn func(color.Appearance)) error {
+	gdbus, err := lookPath("gdbus")
+	if err != nil {
+		return fmt.Errorf("gdbus not found in PATH — it is needed to watch for appearance changes")
+	}
+
+	cmd := command(ctx, gdbus, "monitor", "--session",
+		"--dest", portalDest, "--object-path", portalPath)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting gdbus monitor: %w", err)
+	}
+
+	last, _ := Appearance()
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, "SettingChanged") || !strings.Contains(line, portalKey) {
+			continue
+		}
+		a, ok := parsePortalReply(line)
+		if !ok || a == last {
+			continue
+		}
+		last = a
+		fn(a)
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("gdbus monitor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// signal formats a SettingChanged line as gdbus monitor prints it.
+func signal(key, value string) string {
+	return fmt.Sprintf("/org/freedesktop/portal/desktop: org.freedesktop.portal.Settings.SettingChanged ('org.freedesktop.appearance', '%s', <%s>)", key, value)
+}
+
+// fakeGdbus makes gdbus calls re-run the test binary as
+// TestHelperProcess, which plays the part of the settings portal.
+func fakeGdbus(t *testing.T) {
+	t.Helper()
+	origLook, origCommand := lookPath, command
+	t.Cleanup(func() { lookPath, command = origLook, origCommand })
+
+	lookPath = func(name string) (string, error) {
+		if name == "gdbus" {
+			return name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	command = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		args = append([]string{"-test.run=TestHelperProcess", "--", name}, args...)
+		cmd := exec.CommandContext(ctx, os.Args[0], args...)
+		cmd.Env = append(os.Environ(), "WORKSPACE_COLOURS_FAKE_PORTAL=1")
+		return cmd
+	}
+}
+
+// TestHelperProcess is not a real test: fakeGdbus runs it in place of
+// gdbus. A call reads back dark; a monitor reports a few changes and exits.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("WORKSPACE_COLOURS_FAKE_PORTAL") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	switch {
+	case slices.Contains(args, "call"):
+		fmt.Println("(<<uint32 1>>,)")
+	case slices.Contains(args, "monitor"):
+		fmt.Println("The name org.freedesktop.portal.Desktop is owned by :1.12")
+		fmt.Println(signal("contrast", "uint32 1"))
+		fmt.Println(signal("color-scheme", "uint32 2"))
+		fmt.Println(signal("color-scheme", "uint32 2"))
+		fmt.Println(signal("accent-color", "(0.2, 0.4, 0.8)"))
+		fmt.Println(signal("color-scheme", "uint32 0"))
+	default:
+		fmt.Fprintf(os.Stderr, "unexpected arguments %q\n", args)
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
+
+func TestAppearance(t *testing.T) {
+	fakeGdbus(t)
+	got, err := Appearance()
+	if err != nil {
+		t.Fatalf("Appearance: %v", err)
+	}
+	if got != color.Dark {
+		t.Errorf("Appearance() = %q, want dark", got)
+	}
+}
+
+func TestAppearanceWithoutTools(t *testing.T) {
+	orig := lookPath
+	t.Cleanup(func() { lookPath = orig })
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+
+	if _, err := Appearance(); err == nil {
+		t.Error("Appearance succeeded with neither gdbus nor dbus-send")
+	}
+	if err := Watch(context.Background(), func(color.Appearance) {}); err == nil {
+		t.Error("Watch succeeded without gdbus")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	fakeGdbus(t)
+	var got []color.Appearance
+	err := Watch(context.Background(), func(a color.Appearance) {
+		got = append(got, a)
+	})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	// The starting preference is dark, so only the flip to light and back
+	// is reported; the repeat and other settings are not.
+	want := []color.Appearance{color.Light, color.Dark}
+	if !slices.Equal(got, want) {
+		t.Errorf("Watch reported %q, want %q", got, want)
+	}
+}
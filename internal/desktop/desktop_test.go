@@ -0,0 +1,32 @@
+package desktop
+
+import (
+	"testing"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+func TestParsePortalReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  color.Appearance
+		ok    bool
+	}{
+		{"gdbus dark", "(<<uint32 1>>,)\n", color.Dark, true},
+		{"gdbus light", "(<<uint32 2>>,)\n", color.Light, true},
+		{"gdbus no preference", "(<<uint32 0>>,)\n", color.Dark, true},
+		{"dbus-send light", "   variant       variant          uint32 2\n", color.Light, true},
+		{"signal", "/org/freedesktop/portal/desktop: org.freedesktop.portal.Settings.SettingChanged ('org.freedesktop.appearance', 'color-scheme', <uint32 2>)", color.Light, true},
+		{"unknown value", "(<<uint32 7>>,)\n", "", false},
+		{"no value", "Error: GDBus.Error:org.freedesktop.portal.Error.NotFound: Requested setting not found", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parsePortalReply(tt.reply)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parsePortalReply(%q) = %q, %v; want %q, %v", tt.reply, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -33,13 +33,13 @@ func ConfigureCursor(scheme *color.Scheme, projectDir string) error {
 	// Set the color customizations.
 	settings["workbench.colorCustomizations"] = map[string]string{
 		"titleBar.activeBackground":   scheme.Accent,
-		"titleBar.activeForeground":   "#ffffff",
+		"titleBar.activeForeground":   scheme.TitleFG(),
 		"titleBar.inactiveBackground": scheme.AccentDim,
-		"titleBar.inactiveForeground": "#cccccc",
+		"titleBar.inactiveForeground": scheme.TitleFGInactive(),
 		"activityBar.background":      scheme.AccentDim,
-		"activityBar.foreground":      "#ffffff",
+		"activityBar.foreground":      scheme.TitleFG(),
 		"statusBar.background":        scheme.AccentDim,
-		"statusBar.foreground":        "#ffffff",
+		"statusBar.foreground":        scheme.TitleFG(),
 		"sideBar.border":              scheme.Accent,
 		"panel.border":                scheme.Accent,
 	}
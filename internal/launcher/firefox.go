@@ -218,7 +218,7 @@ func EnsureFirefoxProfile(scheme *color.Scheme) error {
   --toolbar-bgcolor: %s !important;
   --toolbar-color: %s !important;
   --tab-selected-bgcolor: %s !important;
-  --tab-selected-textcolor: #ffffff !important;
+  --tab-selected-textcolor: %s !important;
   --lwt-accent-color: #%s !important;
   --lwt-text-color: %s !important;
 }
@@ -234,7 +234,7 @@ func EnsureFirefoxProfile(scheme *color.Scheme) error {
 .tabbrowser-tab[selected="true"] {
   background-color: %s !important;
 }
-`, scheme.Accent, "#"+scheme.GhosttyFG, scheme.Accent,
+`, scheme.Accent, "#"+scheme.GhosttyFG, scheme.Accent, scheme.TitleFG(),
 		scheme.GhosttyBG, "#"+scheme.GhosttyFG,
 		scheme.GhosttyBG, scheme.AccentDim, scheme.Accent)
 
@@ -254,16 +254,18 @@ func EnsureFirefoxProfile(scheme *color.Scheme) error {
 	return nil
 }
 
-// LaunchFirefox opens Firefox with the workspace-themed profile.
+// LaunchFirefox opens Firefox with the workspace-themed profile, loading any
+// urls given.
 // Returns info about the launched process for session tracking.
-func LaunchFirefox(scheme *color.Scheme) (*LaunchedProcess, error) {
+func LaunchFirefox(scheme *color.Scheme, urls ...string) (*LaunchedProcess, error) {
 	bin, _, err := findFirefox()
 	if err != nil {
 		return nil, err
 	}
 
 	name := profileName(scheme)
-	cmd := exec.Command(bin, "-P", name, "-no-remote")
+	args := append([]string{"-P", name, "-no-remote"}, urls...)
+	cmd := exec.Command(bin, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Start(); err != nil {
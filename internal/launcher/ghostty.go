@@ -10,23 +10,39 @@ import (
 	"github.com/strickvl/workspace-colours/internal/color"
 )
 
-const ghosttyThemeDir = ".config/ghostty/themes"
+const ghosttyThemeDir = "ghostty/themes"
 const ghosttyThemePrefix = "workspace-"
 
 // themeFileName returns the Ghostty theme file name for a color scheme.
+// Light variants get their own file so dark and light windows of the same
+// scheme can be open side by side.
 func themeFileName(scheme *color.Scheme) string {
+	if scheme.IsLight() {
+		return ghosttyThemePrefix + scheme.Name + "-light"
+	}
 	return ghosttyThemePrefix + scheme.Name
 }
 
-// EnsureGhosttyTheme writes a Ghostty theme file for the given scheme if it
-// doesn't already exist. Theme files live in ~/.config/ghostty/themes/.
-func EnsureGhosttyTheme(scheme *color.Scheme) error {
+// ghosttyThemesPath returns the directory Ghostty loads themes from, under
+// $XDG_CONFIG_HOME if it is set (as Ghostty itself does), else ~/.config.
+func ghosttyThemesPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, ghosttyThemeDir), nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("finding home directory: %w", err)
+		return "", fmt.Errorf("finding home directory: %w", err)
 	}
+	return filepath.Join(home, ".config", ghosttyThemeDir), nil
+}
 
-	dir := filepath.Join(home, ghosttyThemeDir)
+// EnsureGhosttyTheme writes a Ghostty theme file for the given scheme if it
+// doesn't already exist. Theme files live in Ghostty's themes directory.
+func EnsureGhosttyTheme(scheme *color.Scheme) error {
+	dir, err := ghosttyThemesPath()
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return fmt.Errorf("creating theme directory: %w", err)
 	}
@@ -37,8 +53,8 @@ func EnsureGhosttyTheme(scheme *color.Scheme) error {
 foreground = %s
 cursor-color = %s
 selection-background = %s
-selection-foreground = ffffff
-`, scheme.GhosttyBG, scheme.GhosttyFG, scheme.CursorColor, scheme.SelectionBG)
+selection-foreground = %s
+`, scheme.GhosttyBG, scheme.GhosttyFG, scheme.CursorColor, scheme.SelectionBG, scheme.SelectionFG())
 
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("writing theme %s: %w", path, err)
@@ -46,11 +62,48 @@ selection-foreground = ffffff
 	return nil
 }
 
-// LaunchGhostty opens N Ghostty terminal windows with the given color scheme.
-// Each window gets a title derived from the project name and an index.
+// Terminal describes one Ghostty window to open.
+type Terminal struct {
+	Label string
+	// Command is run in the window's shell before it becomes interactive.
+	// Empty means just a shell.
+	Command string
+}
+
+// Terminals returns count windows, taking labels and commands from
+// configured in order and giving the rest default labels.
+func Terminals(count int, configured []Terminal) []Terminal {
+	labels := defaultTerminalLabels(count)
+	terminals := make([]Terminal, count)
+	for i := range terminals {
+		if i < len(configured) {
+			terminals[i] = configured[i]
+		}
+		if terminals[i].Label == "" {
+			terminals[i].Label = labels[i]
+		}
+	}
+	return terminals
+}
+
+// LaunchGhostty opens a Ghostty window with the given color scheme for each
+// terminal. Each window gets a title from the project name and its label.
+// If followSystem is set, both the dark and light themes are installed and
+// Ghostty is told to switch between them with the desktop appearance.
 // Returns info about each launched process for session tracking.
-func LaunchGhostty(scheme *color.Scheme, projectDir string, count int) ([]LaunchedProcess, error) {
-	if err := EnsureGhosttyTheme(scheme); err != nil {
+func LaunchGhostty(scheme *color.Scheme, projectDir string, terminals []Terminal, followSystem bool) ([]LaunchedProcess, error) {
+	themeName := themeFileName(scheme)
+	if followSystem {
+		dark, light := scheme.Variant(color.Dark), scheme.Variant(color.Light)
+		if err := EnsureGhosttyTheme(dark); err != nil {
+			return nil, err
+		}
+		if err := EnsureGhosttyTheme(light); err != nil {
+			return nil, err
+		}
+		// Ghostty's own light:/dark: theme syntax.
+		themeName = fmt.Sprintf("light:%s,dark:%s", themeFileName(light), themeFileName(dark))
+	} else if err := EnsureGhosttyTheme(scheme); err != nil {
 		return nil, err
 	}
 
@@ -60,17 +113,20 @@ func LaunchGhostty(scheme *color.Scheme, projectDir string, count int) ([]Launch
 	}
 
 	projectName := filepath.Base(projectDir)
-	themeName := themeFileName(scheme)
 
 	var launched []LaunchedProcess
-	labels := defaultTerminalLabels(count)
-	for i := range count {
-		title := fmt.Sprintf("%s — %s", projectName, labels[i])
+	for i, t := range terminals {
+		title := fmt.Sprintf("%s — %s", projectName, t.Label)
 		args := []string{
 			fmt.Sprintf("--theme=%s", themeName),
 			fmt.Sprintf("--title=%s", title),
 			fmt.Sprintf("--working-directory=%s", projectDir),
 		}
+		if t.Command != "" {
+			// Run the command, then hand the window over to an
+			// interactive shell so it stays open with the output.
+			args = append(args, "-e", "/bin/sh", "-c", `eval "$1"; exec "${SHELL:-/bin/sh}" -l`, "sh", t.Command)
+		}
 
 		cmd := exec.Command(ghosttyBin, args...)
 		cmd.Stdout = os.Stdout
@@ -81,7 +137,7 @@ func LaunchGhostty(scheme *color.Scheme, projectDir string, count int) ([]Launch
 		launched = append(launched, LaunchedProcess{
 			PID:         cmd.Process.Pid,
 			CommandName: "ghostty",
-			Description: fmt.Sprintf("Ghostty — %s", labels[i]),
+			Description: fmt.Sprintf("Ghostty — %s", t.Label),
 		})
 	}
 	return launched, nil
@@ -117,12 +173,10 @@ func defaultTerminalLabels(n int) []string {
 
 // ListInstalledThemes returns workspace theme names currently installed.
 func ListInstalledThemes() ([]string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := ghosttyThemesPath()
 	if err != nil {
 		return nil, err
 	}
-
-	dir := filepath.Join(home, ghosttyThemeDir)
 	entries, err := os.ReadDir(dir)
 	if os.IsNotExist(err) {
 		return nil, nil
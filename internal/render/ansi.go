@@ -0,0 +1,126 @@
+// Package render draws colour schemes for people to look at: ANSI swatches
+// in the terminal, and SVG/PNG cards for documentation.
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// Truecolor reports whether a COLORTERM value advertises 24-bit colour.
+func Truecolor(colorterm string) bool {
+	switch strings.ToLower(colorterm) {
+	case "truecolor", "24bit":
+		return true
+	}
+	return false
+}
+
+// ansiPainter writes SGR escape sequences in either 24-bit or 256-colour
+// form.
+type ansiPainter struct {
+	truecolor bool
+}
+
+const ansiReset = "\x1b[0m"
+
+// paint returns text drawn with the given foreground on the given
+// background (both hex colours).
+func (p ansiPainter) paint(text, fg, bg string) string {
+	return p.sgr(38, fg) + p.sgr(48, bg) + text + ansiReset
+}
+
+func (p ansiPainter) sgr(kind int, hex string) string {
+	c := color.MustParseHex(hex)
+	if p.truecolor {
+		r, g, b := to8(c.R), to8(c.G), to8(c.B)
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", kind, r, g, b)
+	}
+	return fmt.Sprintf("\x1b[%d;5;%dm", kind, xterm256(c))
+}
+
+func to8(v float64) int {
+	return int(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// cubeLevels are the channel values of the xterm 6×6×6 colour cube.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// xterm256 returns the xterm-256 palette index closest to c, choosing
+// between the colour cube (16–231) and the grey ramp (232–255) by
+// perceptual distance.
+func xterm256(c color.RGB) int {
+	r, g, b := to8(c.R), to8(c.G), to8(c.B)
+
+	nearest := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(l-v) < abs(cubeLevels[best]-v) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearest(r), nearest(g), nearest(b)
+	cube := color.RGB{
+		R: float64(cubeLevels[ri]) / 255,
+		G: float64(cubeLevels[gi]) / 255,
+		B: float64(cubeLevels[bi]) / 255,
+	}
+
+	// The grey ramp runs from 8 to 238 in steps of 10.
+	step := max(0, min(23, ((r+g+b)/3-8+5)/10))
+	v := float64(8+10*step) / 255
+	grey := color.RGB{R: v, G: v, B: v}
+
+	if color.DeltaE2000(c, grey) < color.DeltaE2000(c, cube) {
+		return 232 + step
+	}
+	return 16 + 36*ri + 6*gi + bi
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Terminal writes a swatch line for each scheme: the terminal background
+// with sample text, selection and cursor; the accent title bar; the dimmed
+// accent; the base border colour; and the projects using the scheme.
+// usedBy maps scheme names to project names.
+func Terminal(w io.Writer, schemes []*color.Scheme, usedBy map[string][]string, truecolor bool) error {
+	p := ansiPainter{truecolor: truecolor}
+
+	width := 0
+	for _, s := range schemes {
+		width = max(width, len(s.Name))
+	}
+
+	var b strings.Builder
+	for _, s := range schemes {
+		fmt.Fprintf(&b, "%-*s  ", width, s.Name)
+		b.WriteString(p.paint(" ~/project $ ", s.GhosttyFG, s.GhosttyBG))
+		b.WriteString(p.paint("selected", s.SelectionFG(), s.SelectionBG))
+		b.WriteString(p.paint(" ", s.GhosttyFG, s.GhosttyBG))
+		b.WriteString(p.paint(" ", s.CursorColor, s.CursorColor))
+		b.WriteString(p.paint(" ", s.GhosttyFG, s.GhosttyBG))
+		b.WriteString("  ")
+		b.WriteString(p.paint(" Title bar ", s.TitleFG(), s.Accent))
+		b.WriteString(p.paint(" inactive ", s.TitleFGInactive(), s.AccentDim))
+		b.WriteString("  ")
+		b.WriteString(p.paint("    ", s.Base, s.Base))
+		fmt.Fprintf(&b, " %s", s.Base)
+		if projects := usedBy[s.Name]; len(projects) > 0 {
+			fmt.Fprintf(&b, "  %s", strings.Join(projects, ", "))
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
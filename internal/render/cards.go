@@ -0,0 +1,122 @@
+package render
+
+import (
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// Card is one labelled swatch: a miniature workspace window drawn in a
+// scheme's colours, with Title in the title bar and Subtitle in the status
+// bar.
+type Card struct {
+	Title    string
+	Subtitle string
+	Scheme   *color.Scheme
+}
+
+// SchemeCards returns a card per scheme, titled with the scheme name.
+func SchemeCards(schemes []*color.Scheme) []Card {
+	cards := make([]Card, len(schemes))
+	for i, s := range schemes {
+		cards[i] = Card{Title: s.Name, Subtitle: s.Base, Scheme: s}
+	}
+	return cards
+}
+
+// Layout constants, in pixels. Text is monospaced with a fixed advance so
+// the SVG and PNG output line up the same way.
+const (
+	cardW      = 300
+	cardH      = 170
+	cardGap    = 20
+	margin     = 20
+	maxColumns = 4
+	border     = 5
+	barH       = 30
+	charW      = 12 // advance per character
+	capH       = 14 // height of capital letters
+	textPad    = 12
+)
+
+// shape is a filled rectangle or a run of text in the drawing.
+type shape struct {
+	X, Y, W, H int
+	Fill       string // hex colour
+	// Text, if set, is drawn with its baseline at Y instead of a rectangle.
+	Text string
+}
+
+// drawing is a backend-independent list of shapes.
+type drawing struct {
+	W, H   int
+	BG     string
+	Shapes []shape
+}
+
+func (d *drawing) rect(x, y, w, h int, fill string) {
+	d.Shapes = append(d.Shapes, shape{X: x, Y: y, W: w, H: h, Fill: fill})
+}
+
+func (d *drawing) text(x, y int, fill, s string) {
+	d.Shapes = append(d.Shapes, shape{X: x, Y: y, Fill: fill, Text: s})
+}
+
+// layout arranges cards in a grid and draws each one.
+func layout(cards []Card) *drawing {
+	cols := min(maxColumns, max(1, len(cards)))
+	rows := (len(cards) + cols - 1) / cols
+	d := &drawing{
+		W:  2*margin + cols*cardW + (cols-1)*cardGap,
+		H:  2*margin + rows*cardH + max(0, rows-1)*cardGap,
+		BG: "#f4f4f4",
+	}
+	for i, c := range cards {
+		x := margin + (i%cols)*(cardW+cardGap)
+		y := margin + (i/cols)*(cardH+cardGap)
+		drawCard(d, x, y, c)
+	}
+	return d
+}
+
+// maxChars is how many characters fit across a card.
+const maxChars = (cardW - 2*border - 2*textPad) / charW
+
+func clip(s string) string {
+	r := []rune(s)
+	if len(r) > maxChars {
+		return string(r[:maxChars-1]) + "…"
+	}
+	return s
+}
+
+func drawCard(d *drawing, x, y int, c Card) {
+	s := c.Scheme
+	innerX, innerW := x+border, cardW-2*border
+	baseline := func(top, h int) int { return top + (h+capH)/2 }
+
+	// Window border in the base colour, as JankyBorders draws it.
+	d.rect(x, y, cardW, cardH, s.Base)
+
+	// Title bar in the accent colour.
+	titleY := y + border
+	d.rect(innerX, titleY, innerW, barH, s.Accent)
+	d.text(innerX+textPad, baseline(titleY, barH), s.TitleFG(), clip(c.Title))
+
+	// Terminal body: a prompt, a selected line and the cursor.
+	statusY := y + cardH - border - barH
+	bodyY := titleY + barH
+	d.rect(innerX, bodyY, innerW, statusY-bodyY, s.GhosttyBG)
+	lineH := capH + 10
+	line1 := bodyY + 10
+	d.text(innerX+textPad, baseline(line1, lineH), s.GhosttyFG, "$ git status")
+	line2 := line1 + lineH
+	sel := "selected text"
+	d.rect(innerX+textPad-3, line2, len(sel)*charW+6, lineH, s.SelectionBG)
+	d.text(innerX+textPad, baseline(line2, lineH), s.SelectionFG(), sel)
+	line3 := line2 + lineH
+	d.text(innerX+textPad, baseline(line3, lineH), s.GhosttyFG, "$")
+	d.rect(innerX+textPad+2*charW, line3+3, charW-2, lineH-6, s.CursorColor)
+
+	// Status bar in the dimmed accent.
+	d.rect(innerX, statusY, innerW, barH, s.AccentDim)
+	d.text(innerX+textPad, baseline(statusY, barH), s.TitleFGInactive(), clip(c.Subtitle))
+}
@@ -0,0 +1,60 @@
+package render
+
+import (
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// glyphScale is how many pixels each font dot becomes. The 5×7 font at
+// scale 2 gives capH-high capitals and a charW advance.
+const glyphScale = 2
+
+// PNG writes the cards as a PNG image, drawing text with the embedded
+// bitmap font so no system fonts or external tools are needed.
+func PNG(w io.Writer, cards []Card) error {
+	d := layout(cards)
+	img := image.NewRGBA(image.Rect(0, 0, d.W, d.H))
+	draw.Draw(img, img.Bounds(), image.NewUniform(rgba(d.BG)), image.Point{}, draw.Src)
+
+	for _, s := range d.Shapes {
+		fill := image.NewUniform(rgba(s.Fill))
+		if s.Text == "" {
+			draw.Draw(img, image.Rect(s.X, s.Y, s.X+s.W, s.Y+s.H), fill, image.Point{}, draw.Src)
+			continue
+		}
+		drawText(img, s.X, s.Y, s.Text, fill)
+	}
+	return png.Encode(w, img)
+}
+
+// drawText draws s with its baseline at y.
+func drawText(img draw.Image, x, y int, s string, fill image.Image) {
+	top := y - glyphRows*glyphScale
+	for _, r := range s {
+		g := glyph(r)
+		top := top
+		if descenders[r] {
+			top += descent * glyphScale
+		}
+		for row := range glyphRows {
+			for col := range glyphCols {
+				if g[row]&(1<<(glyphCols-1-col)) == 0 {
+					continue
+				}
+				px, py := x+col*glyphScale, top+row*glyphScale
+				draw.Draw(img, image.Rect(px, py, px+glyphScale, py+glyphScale), fill, image.Point{}, draw.Src)
+			}
+		}
+		x += charW
+	}
+}
+
+func rgba(hex string) imagecolor.RGBA {
+	c := color.MustParseHex(hex)
+	return imagecolor.RGBA{R: uint8(to8(c.R)), G: uint8(to8(c.G)), B: uint8(to8(c.B)), A: 0xff}
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// SVG writes the cards as an SVG image.
+func SVG(w io.Writer, cards []Card) error {
+	d := layout(cards)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", d.W, d.H, d.W, d.H)
+	// A 20px monospace font advances about 12px per character, matching
+	// charW, and has roughly capH-high capitals.
+	b.WriteString(`<style>text { font-family: ui-monospace, "SF Mono", Menlo, Consolas, monospace; font-size: 20px; }</style>` + "\n")
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", d.BG)
+	for _, s := range d.Shapes {
+		if s.Text != "" {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" fill="%s">%s</text>`+"\n", s.X, s.Y, hashed(s.Fill), html.EscapeString(s.Text))
+			continue
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", s.X, s.Y, s.W, s.H, hashed(s.Fill))
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hashed adds a "#" prefix to a hex colour if it's missing.
+func hashed(hex string) string {
+	if strings.HasPrefix(hex, "#") {
+		return hex
+	}
+	return "#" + hex
+}
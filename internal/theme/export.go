@@ -0,0 +1,237 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// themeData is what the text templates below see.
+type themeData struct {
+	Name   string
+	Scheme string
+	BG     string
+	FG     string
+	Cursor string
+	SelBG  string
+	SelFG  string
+	Accent string
+	Dim    string
+	Title  string
+	Faded  string
+	Base   string
+}
+
+func newThemeData(s *color.Scheme) themeData {
+	return themeData{
+		Name:   themeName(s),
+		Scheme: s.Name,
+		BG:     bare(s.GhosttyBG),
+		FG:     bare(s.GhosttyFG),
+		Cursor: bare(s.CursorColor),
+		SelBG:  bare(s.SelectionBG),
+		SelFG:  bare(s.SelectionFG()),
+		Accent: bare(s.Accent),
+		Dim:    bare(s.AccentDim),
+		Title:  bare(s.TitleFG()),
+		Faded:  bare(s.TitleFGInactive()),
+		Base:   bare(s.Base),
+	}
+}
+
+func templateWriter(text string) Writer {
+	t := template.Must(template.New("").Parse(text))
+	return func(w io.Writer, s *color.Scheme) error {
+		return t.Execute(w, newThemeData(s))
+	}
+}
+
+var writeAlacritty = templateWriter(`# {{.Name}} — generated by workspace-colours
+[colors.primary]
+background = "#{{.BG}}"
+foreground = "#{{.FG}}"
+
+[colors.cursor]
+text = "#{{.BG}}"
+cursor = "#{{.Cursor}}"
+
+[colors.selection]
+text = "#{{.SelFG}}"
+background = "#{{.SelBG}}"
+`)
+
+var writeKitty = templateWriter(`# {{.Name}} — generated by workspace-colours
+background #{{.BG}}
+foreground #{{.FG}}
+cursor #{{.Cursor}}
+cursor_text_color #{{.BG}}
+selection_background #{{.SelBG}}
+selection_foreground #{{.SelFG}}
+active_border_color #{{.Base}}
+active_tab_background #{{.Accent}}
+active_tab_foreground #{{.Title}}
+inactive_tab_background #{{.Dim}}
+inactive_tab_foreground #{{.Faded}}
+tab_bar_background #{{.Dim}}
+`)
+
+var writeWezTermTOML = templateWriter(`# {{.Name}} — generated by workspace-colours
+[colors]
+background = "#{{.BG}}"
+foreground = "#{{.FG}}"
+cursor_bg = "#{{.Cursor}}"
+cursor_border = "#{{.Cursor}}"
+cursor_fg = "#{{.BG}}"
+selection_bg = "#{{.SelBG}}"
+selection_fg = "#{{.SelFG}}"
+
+[colors.tab_bar]
+background = "#{{.Dim}}"
+
+[colors.tab_bar.active_tab]
+bg_color = "#{{.Accent}}"
+fg_color = "#{{.Title}}"
+
+[colors.tab_bar.inactive_tab]
+bg_color = "#{{.Dim}}"
+fg_color = "#{{.Faded}}"
+
+[metadata]
+name = "{{.Name}}"
+`)
+
+var writeWezTermLua = templateWriter(`-- {{.Name}} — generated by workspace-colours
+-- Usage: config.colors = require("{{.Name}}")
+return {
+  background = "#{{.BG}}",
+  foreground = "#{{.FG}}",
+  cursor_bg = "#{{.Cursor}}",
+  cursor_border = "#{{.Cursor}}",
+  cursor_fg = "#{{.BG}}",
+  selection_bg = "#{{.SelBG}}",
+  selection_fg = "#{{.SelFG}}",
+  tab_bar = {
+    background = "#{{.Dim}}",
+    active_tab = { bg_color = "#{{.Accent}}", fg_color = "#{{.Title}}" },
+    inactive_tab = { bg_color = "#{{.Dim}}", fg_color = "#{{.Faded}}" },
+  },
+}
+`)
+
+var writeFoot = templateWriter(`# {{.Name}} — generated by workspace-colours
+[cursor]
+color={{.BG}} {{.Cursor}}
+
+[colors]
+background={{.BG}}
+foreground={{.FG}}
+selection-background={{.SelBG}}
+selection-foreground={{.SelFG}}
+`)
+
+var writeXresources = templateWriter(`! {{.Name}} — generated by workspace-colours
+*.background: #{{.BG}}
+*.foreground: #{{.FG}}
+*.cursorColor: #{{.Cursor}}
+*.highlightColor: #{{.SelBG}}
+*.highlightTextColor: #{{.SelFG}}
+`)
+
+// writeBase16 writes a classic base16 scheme. base00–base07 are a ramp from
+// the background to the foreground (with base02 as the selection colour);
+// base08–base0F are the accent hues from the derived ANSI palette.
+func writeBase16(w io.Writer, s *color.Scheme) error {
+	p := ansiPalette(s)
+	// red, orange, yellow, green, cyan, blue, magenta, brown
+	orange := color.OKLCH{L: 0.72, C: 0.14, H: 55}
+	brown := color.OKLCH{L: 0.55, C: 0.09, H: 50}
+	if s.IsLight() {
+		orange.L, brown.L = 0.58, 0.45
+	}
+	values := []string{
+		bare(s.GhosttyBG),
+		ramp(s, 0.08),
+		bare(s.SelectionBG),
+		ramp(s, 0.45),
+		ramp(s, 0.75),
+		bare(s.GhosttyFG),
+		ramp(s, 1.04),
+		ramp(s, 1.08),
+		p[1], orange.RGB().Hex(), p[3], p[2], p[6], p[4], p[5], brown.RGB().Hex(),
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "scheme: %q\n", themeName(s))
+	fmt.Fprintf(&b, "author: %q\n", "workspace-colours")
+	for i, v := range values {
+		fmt.Fprintf(&b, "base%02X: %q\n", i, v)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// windowsTerminalScheme is an entry for the "schemes" list in Windows
+// Terminal's settings.json. Field order follows the documentation.
+type windowsTerminalScheme struct {
+	Name                string `json:"name"`
+	Background          string `json:"background"`
+	Foreground          string `json:"foreground"`
+	CursorColor         string `json:"cursorColor"`
+	SelectionBackground string `json:"selectionBackground"`
+	Black               string `json:"black"`
+	Red                 string `json:"red"`
+	Green               string `json:"green"`
+	Yellow              string `json:"yellow"`
+	Blue                string `json:"blue"`
+	Purple              string `json:"purple"`
+	Cyan                string `json:"cyan"`
+	White               string `json:"white"`
+	BrightBlack         string `json:"brightBlack"`
+	BrightRed           string `json:"brightRed"`
+	BrightGreen         string `json:"brightGreen"`
+	BrightYellow        string `json:"brightYellow"`
+	BrightBlue          string `json:"brightBlue"`
+	BrightPurple        string `json:"brightPurple"`
+	BrightCyan          string `json:"brightCyan"`
+	BrightWhite         string `json:"brightWhite"`
+}
+
+func writeWindowsTerminal(w io.Writer, s *color.Scheme) error {
+	p := ansiPalette(s)
+	for i := range p {
+		p[i] = "#" + strings.ToUpper(p[i])
+	}
+	up := func(c string) string { return strings.ToUpper(hex(c)) }
+	data, err := json.MarshalIndent(windowsTerminalScheme{
+		Name:                themeName(s),
+		Background:          up(s.GhosttyBG),
+		Foreground:          up(s.GhosttyFG),
+		CursorColor:         up(s.CursorColor),
+		SelectionBackground: up(s.SelectionBG),
+		Black:               p[0],
+		Red:                 p[1],
+		Green:               p[2],
+		Yellow:              p[3],
+		Blue:                p[4],
+		Purple:              p[5],
+		Cyan:                p[6],
+		White:               p[7],
+		BrightBlack:         p[8],
+		BrightRed:           p[9],
+		BrightGreen:         p[10],
+		BrightYellow:        p[11],
+		BrightBlue:          p[12],
+		BrightPurple:        p[13],
+		BrightCyan:          p[14],
+		BrightWhite:         p[15],
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,55 @@
+package theme
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestExport compares every format's output for the built-in blue scheme,
+// and its light variant, with testdata/<format>.golden. Run with -update
+// after an intended change to regenerate them.
+func TestExport(t *testing.T) {
+	dark := color.ByName("blue")
+	if dark == nil {
+		t.Fatal("built-in scheme blue is missing")
+	}
+	light := dark.Variant(color.Light)
+
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			for _, s := range []*color.Scheme{dark, light} {
+				if err := Export(&buf, format, s); err != nil {
+					t.Fatalf("Export %s: %v", s.Name, err)
+				}
+			}
+			golden := filepath.Join("testdata", format+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s:\n%s", golden, buf.Bytes())
+			}
+		})
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if err := Export(&bytes.Buffer{}, "nope", color.ByName("blue")); err == nil {
+		t.Error("Export accepted an unknown format")
+	}
+}
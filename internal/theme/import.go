@@ -0,0 +1,427 @@
+package theme
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+	"github.com/strickvl/workspace-colours/internal/toml"
+)
+
+// Imported holds the colours read from another tool's theme file. Any field
+// the file didn't define is empty.
+type Imported struct {
+	// Name is the theme's own name if the file has one, else derived from
+	// the file name.
+	Name       string
+	Background string
+	Foreground string
+	Cursor     string
+	Selection  string
+	// Accents are the file's chromatic palette colours (ANSI red..cyan or
+	// base16 base08..base0F), used to pick a base colour.
+	Accents []string
+}
+
+// importers maps format names to their parsers.
+var importers = map[string]func([]byte) (*Imported, error){
+	"iterm":     parseITerm,
+	"base16":    parseBase16,
+	"alacritty": parseAlacritty,
+}
+
+// ImportFormats returns the names of all import formats.
+func ImportFormats() []string {
+	return []string{"alacritty", "base16", "iterm"}
+}
+
+// DetectFormat guesses a theme file's format from its extension.
+func DetectFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".itermcolors":
+		return "iterm", nil
+	case ".yaml", ".yml":
+		return "base16", nil
+	case ".toml":
+		return "alacritty", nil
+	}
+	return "", fmt.Errorf("can't tell the format of %s from its extension (use --format: %v)", path, ImportFormats())
+}
+
+// Import parses a theme file in the given format.
+func Import(format string, data []byte) (*Imported, error) {
+	parse, ok := importers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q (available: %v)", format, ImportFormats())
+	}
+	im, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if im.Background == "" || im.Foreground == "" {
+		return nil, fmt.Errorf("theme does not define both a background and a foreground colour")
+	}
+	return im, nil
+}
+
+// Scheme maps the imported colours onto a Scheme. Background, foreground,
+// cursor and selection are taken from the file; the accent fields are
+// generated from base, or from a base picked from the file's colours if base
+// is empty. If the theme is light, it becomes the scheme's light variant and
+// the dark variant is generated.
+func (im *Imported) Scheme(name, base string) (*color.Scheme, error) {
+	if base == "" {
+		base = "#" + im.pickBase().Hex()
+	}
+	s, err := color.Generate(name, base)
+	if err != nil {
+		return nil, fmt.Errorf("base colour: %w", err)
+	}
+
+	target := s
+	light := color.MustParseHex(im.Background).OKLCH().L > 0.6
+	if light {
+		target = s.Variant(color.Light)
+	}
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&target.GhosttyBG, im.Background},
+		{&target.GhosttyFG, im.Foreground},
+		{&target.CursorColor, im.Cursor},
+		{&target.SelectionBG, im.Selection},
+	} {
+		if f.src != "" {
+			*f.dst = color.MustParseHex(f.src).Hex()
+		}
+	}
+	legibleAccents(target)
+	if light {
+		target.Appearance = ""
+		s.LightVariant = target
+	}
+	return s, nil
+}
+
+// legibleAccents moves the lightness of s's accent colours away from its
+// text until everything drawn on them reaches LevelAA, the contrast
+// palettes.toml is checked at unless min_contrast says otherwise. The
+// accents are generated for the scheme's own text colours, which an
+// imported foreground, like Solarized Light's mid-grey, may not resemble.
+func legibleAccents(s *color.Scheme) {
+	step := -0.01
+	if s.IsLight() {
+		step = 0.01
+	}
+	for _, accent := range []*string{&s.Accent, &s.AccentDim} {
+		o := color.MustParseHex(*accent).OKLCH()
+		for !legibleOn(s, *accent) && o.L > 0 && o.L < 1 {
+			o.L = math.Max(0, math.Min(1, o.L+step))
+			*accent = "#" + o.RGB().Hex()
+		}
+	}
+}
+
+// legibleOn reports whether every pair s draws on the background bg
+// reaches LevelAA.
+func legibleOn(s *color.Scheme, bg string) bool {
+	for _, p := range s.Pairs() {
+		if p.BG == bg && color.Contrast(color.MustParseHex(p.FG), color.MustParseHex(bg)) < float64(color.LevelAA) {
+			return false
+		}
+	}
+	return true
+}
+
+// pickBase chooses the colour a scheme's accents are generated from: the
+// cursor if it is clearly coloured, else the hue the background is tinted
+// with, else the most saturated palette colour.
+func (im *Imported) pickBase() color.RGB {
+	if im.Cursor != "" {
+		if c := color.MustParseHex(im.Cursor); c.OKLCH().C >= 0.08 {
+			return c
+		}
+	}
+	if bg := color.MustParseHex(im.Background).OKLCH(); bg.C >= 0.015 {
+		return color.OKLCH{L: 0.64, C: 0.19, H: bg.H}.RGB()
+	}
+	best, bestC := color.MustParseHex(im.Foreground), -1.0
+	for _, a := range im.Accents {
+		c := color.MustParseHex(a)
+		if ch := c.OKLCH().C; ch > bestC {
+			best, bestC = c, ch
+		}
+	}
+	return best
+}
+
+// SchemeName turns a theme or file name into a valid scheme name.
+func SchemeName(s string) string {
+	s = strings.TrimSuffix(filepath.Base(s), filepath.Ext(s))
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// normalize accepts "#rrggbb", "rrggbb" or "0xrrggbb" and returns "#rrggbb".
+func normalize(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	c, err := color.ParseHex(s)
+	if err != nil {
+		return "", err
+	}
+	return "#" + c.Hex(), nil
+}
+
+// parseITerm reads an iTerm2 .itermcolors property list. Each colour is a
+// dict of "Red/Green/Blue Component" reals in [0, 1].
+func parseITerm(data []byte) (*Imported, error) {
+	top, err := parsePlist(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing property list: %w", err)
+	}
+
+	colours := make(map[string]string)
+	for key, v := range top {
+		comp, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		r, okR := comp["Red Component"].(float64)
+		g, okG := comp["Green Component"].(float64)
+		b, okB := comp["Blue Component"].(float64)
+		if !okR || !okG || !okB {
+			return nil, fmt.Errorf("%q is missing a colour component", key)
+		}
+		colours[key] = "#" + color.RGB{R: r, G: g, B: b}.Hex()
+	}
+
+	im := &Imported{
+		Background: colours["Background Color"],
+		Foreground: colours["Foreground Color"],
+		Cursor:     colours["Cursor Color"],
+		Selection:  colours["Selection Color"],
+	}
+	for i := 1; i <= 6; i++ {
+		if c := colours[fmt.Sprintf("Ansi %d Color", i)]; c != "" {
+			im.Accents = append(im.Accents, c)
+		}
+	}
+	return im, nil
+}
+
+// parsePlist decodes the top-level <dict> of an XML property list. Values
+// are float64 for <real>/<integer>, string for <string>, and map[string]any
+// for nested dicts; other types are skipped.
+func parsePlist(data []byte) (map[string]any, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("no <dict> found: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "dict" {
+			return parsePlistDict(dec)
+		}
+	}
+}
+
+func parsePlistDict(dec *xml.Decoder) (map[string]any, error) {
+	out := make(map[string]any)
+	var key string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.EndElement:
+			if t.Name.Local == "dict" {
+				return out, nil
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "dict":
+				d, err := parsePlistDict(dec)
+				if err != nil {
+					return nil, err
+				}
+				out[key] = d
+			case "key", "string", "real", "integer":
+				var text string
+				if err := dec.DecodeElement(&text, &t); err != nil {
+					return nil, err
+				}
+				text = strings.TrimSpace(text)
+				switch t.Name.Local {
+				case "key":
+					key = text
+				case "string":
+					out[key] = text
+				default:
+					f, err := strconv.ParseFloat(text, 64)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", key, err)
+					}
+					out[key] = math.Max(0, math.Min(1, f))
+				}
+			default:
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
+
+var base16Line = regexp.MustCompile(`^\s*(base0[0-9A-Fa-f]|scheme|name)\s*:\s*(.*)$`)
+
+// parseBase16 reads a base16 YAML scheme, in either the classic flat
+// layout or the newer one with a nested "palette:" block. Only the scheme
+// name and baseXX keys are read, so a full YAML parser isn't needed.
+func parseBase16(data []byte) (*Imported, error) {
+	base := make(map[string]string)
+	var name string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		m := base16Line.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		key, val := strings.ToLower(m[1]), yamlScalar(m[2])
+		if key == "scheme" || key == "name" {
+			if name == "" {
+				name = val
+			}
+			continue
+		}
+		hex, err := normalize(val)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", line, key, err)
+		}
+		base[key] = hex
+	}
+	if len(base) == 0 {
+		return nil, fmt.Errorf("no base00–base0F colours found")
+	}
+
+	// base16 styling guidelines: base00 is the default background, base05
+	// the default foreground and caret, base02 the selection background.
+	im := &Imported{
+		Name:       name,
+		Background: base["base00"],
+		Foreground: base["base05"],
+		Cursor:     base["base05"],
+		Selection:  base["base02"],
+	}
+	for _, k := range []string{"base08", "base09", "base0a", "base0b", "base0c", "base0d", "base0e"} {
+		if c := base[k]; c != "" {
+			im.Accents = append(im.Accents, c)
+		}
+	}
+	return im, nil
+}
+
+// yamlScalar returns the value of a single-line YAML scalar: the contents
+// of a quoted string, or a bare value with any trailing comment removed.
+func yamlScalar(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 0 && (s[0] == '"' || s[0] == '\'') {
+		if end := strings.IndexByte(s[1:], s[0]); end >= 0 {
+			return s[1 : end+1]
+		}
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseAlacritty reads the [colors] section of an Alacritty TOML config or
+// theme file.
+func parseAlacritty(data []byte) (*Imported, error) {
+	root, err := toml.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	colors, err := root.Table("colors")
+	if err != nil {
+		return nil, err
+	}
+	if colors == nil {
+		return nil, fmt.Errorf("no [colors] section found")
+	}
+
+	get := func(table, key string) (string, error) {
+		t, err := colors.Table(table)
+		if err != nil || t == nil {
+			return "", err
+		}
+		v := t.Get(key)
+		if v == nil {
+			return "", nil
+		}
+		raw, err := v.AsString()
+		if err != nil {
+			return "", err
+		}
+		// Alacritty allows "CellForeground"/"CellBackground" here, which
+		// aren't fixed colours.
+		if strings.HasPrefix(raw, "Cell") {
+			return "", nil
+		}
+		hex, err := normalize(raw)
+		if err != nil {
+			return "", toml.Errorf(v.Line, "colors.%s.%s: %v", table, key, err)
+		}
+		return hex, nil
+	}
+
+	im := &Imported{}
+	fields := []struct {
+		dst        *string
+		table, key string
+	}{
+		{&im.Background, "primary", "background"},
+		{&im.Foreground, "primary", "foreground"},
+		{&im.Cursor, "cursor", "cursor"},
+		{&im.Selection, "selection", "background"},
+	}
+	for _, f := range fields {
+		if *f.dst, err = get(f.table, f.key); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range []string{"red", "green", "yellow", "blue", "magenta", "cyan"} {
+		c, err := get("normal", k)
+		if err != nil {
+			return nil, err
+		}
+		if c != "" {
+			im.Accents = append(im.Accents, c)
+		}
+	}
+	return im, nil
+}
@@ -0,0 +1,91 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// solarizedLight is the base16 Solarized Light scheme, whose mid-grey
+// foreground is far darker than the accents generated for a light theme
+// expect.
+const solarizedLight = `scheme: "Solarized Light"
+author: "Ethan Schoonover (modified by aramisgithub)"
+base00: "fdf6e3"
+base01: "eee8d5"
+base02: "93a1a1"
+base03: "839496"
+base04: "657b83"
+base05: "586e75"
+base06: "073642"
+base07: "002b36"
+base08: "dc322f"
+base09: "cb4b16"
+base0A: "b58900"
+base0B: "859900"
+base0C: "2aa198"
+base0D: "268bd2"
+base0E: "6c71c4"
+base0F: "d33682"
+`
+
+const solarizedDark = `scheme: "Solarized Dark"
+base00: "002b36"
+base01: "073642"
+base02: "586e75"
+base03: "657b83"
+base04: "839496"
+base05: "93a1a1"
+base06: "eee8d5"
+base07: "fdf6e3"
+base08: "dc322f"
+base09: "cb4b16"
+base0A: "b58900"
+base0B: "859900"
+base0C: "2aa198"
+base0D: "268bd2"
+base0E: "6c71c4"
+base0F: "d33682"
+`
+
+// TestImportPassesAudit checks that an imported theme can be saved to
+// palettes.toml at the default contrast level: its own colours are kept
+// and the generated accents are made legible behind them.
+func TestImportPassesAudit(t *testing.T) {
+	tests := []struct {
+		name, src string
+		light     bool
+	}{
+		{"solarized-light", solarizedLight, true},
+		{"solarized-dark", solarizedDark, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im, err := Import("base16", []byte(tt.src))
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			s, err := im.Scheme(tt.name, "")
+			if err != nil {
+				t.Fatalf("Scheme: %v", err)
+			}
+			for _, issue := range color.Audit(s, color.LevelAA) {
+				t.Error(issue)
+			}
+			if _, err := color.ParsePalette([]byte(color.FormatPaletteEntry(s))); err != nil {
+				t.Errorf("palette entry rejected: %v", err)
+			}
+
+			v := s
+			if tt.light {
+				if s.LightVariant == nil {
+					t.Fatal("light theme did not become the light variant")
+				}
+				v = s.LightVariant
+			}
+			if got, want := color.MustParseHex(v.GhosttyFG), color.MustParseHex(im.Foreground); got != want {
+				t.Errorf("foreground = %s, want the imported %s", got.Hex(), want.Hex())
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package theme converts workspace colour schemes to and from the theme
+// formats of other terminals and editors, so teammates on other tools can
+// share the same colours.
+package theme
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/strickvl/workspace-colours/internal/color"
+)
+
+// Writer writes a scheme in one theme format.
+type Writer func(w io.Writer, s *color.Scheme) error
+
+// writers maps format names to their writers.
+var writers = map[string]Writer{
+	"alacritty":        writeAlacritty,
+	"kitty":            writeKitty,
+	"wezterm":          writeWezTermTOML,
+	"wezterm-lua":      writeWezTermLua,
+	"foot":             writeFoot,
+	"xresources":       writeXresources,
+	"base16":           writeBase16,
+	"windows-terminal": writeWindowsTerminal,
+}
+
+// Formats returns the names of all export formats, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(writers))
+	for n := range writers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Export writes s to w in the named format.
+func Export(w io.Writer, format string, s *color.Scheme) error {
+	write, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unknown export format %q (available: %v)", format, Formats())
+	}
+	return write(w, s)
+}
+
+// themeName is the name exported themes are given, matching the Ghostty
+// theme and Firefox profile names.
+func themeName(s *color.Scheme) string {
+	if s.IsLight() {
+		return "workspace-" + s.Name + "-light"
+	}
+	return "workspace-" + s.Name
+}
+
+// hex returns a scheme colour with a "#" prefix, whichever convention the
+// field uses.
+func hex(c string) string {
+	return "#" + color.MustParseHex(c).Hex()
+}
+
+// bare returns a scheme colour without a "#" prefix.
+func bare(c string) string {
+	return color.MustParseHex(c).Hex()
+}
+
+// ansi is the 16-colour terminal palette, in the conventional order: black,
+// red, green, yellow, blue, magenta, cyan, white, then the bright versions.
+type ansi [16]string
+
+// ansiHues are the OKLCH hues of red, green, yellow, blue, magenta and cyan.
+var ansiHues = [6]float64{29, 142, 100, 264, 328, 195}
+
+// ansiPalette derives a 16-colour palette for formats that require one. The
+// six hues are fixed so the palette reads as standard ANSI colours; their
+// lightness is tuned for the variant, and black/white come from the
+// scheme's own background and foreground.
+func ansiPalette(s *color.Scheme) ansi {
+	normalL, brightL := 0.68, 0.80
+	if s.IsLight() {
+		normalL, brightL = 0.52, 0.45
+	}
+	var p ansi
+	for i, h := range ansiHues {
+		p[1+i] = color.OKLCH{L: normalL, C: 0.15, H: h}.RGB().Hex()
+		p[9+i] = color.OKLCH{L: brightL, C: 0.14, H: h}.RGB().Hex()
+	}
+	p[0] = ramp(s, 0.15)
+	p[8] = ramp(s, 0.45)
+	p[7] = ramp(s, 0.85)
+	p[15] = ramp(s, 1.05)
+	return p
+}
+
+// ramp interpolates in OKLCH from the scheme's background (t=0) to its
+// foreground (t=1), extrapolating slightly past either end if asked.
+func ramp(s *color.Scheme, t float64) string {
+	bg := color.MustParseHex(s.GhosttyBG).OKLCH()
+	fg := color.MustParseHex(s.GhosttyFG).OKLCH()
+	l := bg.L + (fg.L-bg.L)*t
+	c := math.Max(0, bg.C+(fg.C-bg.C)*t)
+	return color.OKLCH{L: math.Max(0, math.Min(1, l)), C: c, H: fg.H}.RGB().Hex()
+}
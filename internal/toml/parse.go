@@ -0,0 +1,495 @@
+package toml
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type parser struct {
+	src  string
+	pos  int
+	line int
+	root *Table
+	cur  *Table
+}
+
+// Parse reads a TOML document and returns its root table.
+func Parse(data []byte) (*Table, error) {
+	p := &parser{src: string(data), line: 1, root: newTable(0)}
+	p.cur = p.root
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return p.root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			err = p.parseHeader()
+		} else {
+			err = p.parseKeyValue(p.cur)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return Errorf(p.line, format, args...)
+}
+
+// skipBlank skips spaces, tabs and comments, and newlines too if multiline
+// is set.
+func (p *parser) skipBlank(multiline bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case c == '\n' && multiline:
+			p.pos++
+			p.line++
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine requires that nothing but whitespace or a comment follows on the
+// current line.
+func (p *parser) endOfLine() error {
+	p.skipBlank(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return p.errorf("unexpected %q after value", p.peek())
+	}
+	p.pos++
+	p.line++
+	return nil
+}
+
+func (p *parser) parseHeader() error {
+	line := p.line
+	p.pos++ // '['
+	array := p.peek() == '['
+	if array {
+		p.pos++
+	}
+	p.skipBlank(false)
+	path, err := p.parseKeyPath()
+	if err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.src[p.pos:], closing) {
+		return p.errorf("expected %q to close table header", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := p.descend(p.root, path[:len(path)-1], line)
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	existing := parent.Get(last)
+
+	if array {
+		if existing == nil {
+			existing = &Value{Line: line, kind: KindTableArray}
+			parent.set(last, existing)
+		} else if existing.kind != KindTableArray {
+			return Errorf(line, "key %q is already defined as %s on line %d", last, existing.kind, existing.Line)
+		}
+		t := newTable(line)
+		t.defined = true
+		existing.tables = append(existing.tables, t)
+		p.cur = t
+		return nil
+	}
+
+	if existing == nil {
+		t := newTable(line)
+		t.defined = true
+		parent.set(last, &Value{Line: line, kind: KindTable, table: t})
+		p.cur = t
+		return nil
+	}
+	if existing.kind != KindTable {
+		return Errorf(line, "key %q is already defined as %s on line %d", last, existing.kind, existing.Line)
+	}
+	if existing.table.defined {
+		return Errorf(line, "table %q is already defined on line %d", strings.Join(path, "."), existing.table.Line)
+	}
+	existing.table.defined = true
+	existing.table.Line = line
+	p.cur = existing.table
+	return nil
+}
+
+// descend walks (and creates) the tables along path starting at t. For an
+// array of tables, the most recently declared element is used.
+func (p *parser) descend(t *Table, path []string, line int) (*Table, error) {
+	for _, key := range path {
+		v := t.Get(key)
+		switch {
+		case v == nil:
+			next := newTable(line)
+			t.set(key, &Value{Line: line, kind: KindTable, table: next})
+			t = next
+		case v.kind == KindTable:
+			t = v.table
+		case v.kind == KindTableArray:
+			t = v.tables[len(v.tables)-1]
+		default:
+			return nil, Errorf(line, "key %q is already defined as %s on line %d", key, v.kind, v.Line)
+		}
+	}
+	return t, nil
+}
+
+func (p *parser) parseKeyValue(t *Table) error {
+	line := p.line
+	path, err := p.parseKeyPath()
+	if err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	if p.peek() != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(path, "."))
+	}
+	p.pos++
+	p.skipBlank(false)
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	parent, err := p.descend(t, path[:len(path)-1], line)
+	if err != nil {
+		return err
+	}
+	return parent.set(path[len(path)-1], v)
+}
+
+func (p *parser) parseKeyPath() ([]string, error) {
+	var path []string
+	for {
+		p.skipBlank(false)
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, key)
+		p.skipBlank(false)
+		if p.peek() != '.' {
+			return path, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseKey() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.parseBasicString()
+	case '\'':
+		return p.parseLiteralString()
+	}
+	start := p.pos
+	for !p.eof() && isBareKeyChar(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("expected a key")
+		}
+		return "", p.errorf("unexpected %q where a key was expected", p.peek())
+	}
+	return p.src[start:p.pos], nil
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *parser) parseValue() (*Value, error) {
+	line := p.line
+	switch c := p.peek(); {
+	case c == '"':
+		s, err := p.parseBasicString()
+		return &Value{Line: line, kind: KindString, str: s}, err
+	case c == '\'':
+		s, err := p.parseLiteralString()
+		return &Value{Line: line, kind: KindString, str: s}, err
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case strings.HasPrefix(p.src[p.pos:], "true"):
+		p.pos += 4
+		return &Value{Line: line, kind: KindBool, bool: true}, nil
+	case strings.HasPrefix(p.src[p.pos:], "false"):
+		p.pos += 5
+		return &Value{Line: line, kind: KindBool, bool: false}, nil
+	case c == '+' || c == '-' || c >= '0' && c <= '9' || c == 'i' || c == 'n':
+		return p.parseNumber()
+	case c == 0 || c == '\n':
+		return nil, p.errorf("missing value")
+	}
+	return nil, p.errorf("unexpected %q at start of value", p.peek())
+}
+
+func (p *parser) parseNumber() (*Value, error) {
+	line := p.line
+	start := p.pos
+	for !p.eof() {
+		c := p.peek()
+		if isBareKeyChar(c) || c == '.' || c == '+' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	raw := p.src[start:p.pos]
+	clean := strings.ReplaceAll(raw, "_", "")
+	switch clean {
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return nil, Errorf(line, "unsupported number %q", raw)
+	}
+	if base, digits := intBase(clean); base != 0 {
+		if n, err := strconv.ParseInt(digits, base, 64); err == nil {
+			return &Value{Line: line, kind: KindInteger, num: n}, nil
+		}
+	} else if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return &Value{Line: line, kind: KindFloat, float: f}, nil
+	}
+	if strings.ContainsAny(raw, "-:") && len(raw) >= 8 {
+		return nil, Errorf(line, "dates and times are not supported (%q)", raw)
+	}
+	return nil, Errorf(line, "invalid value %q", raw)
+}
+
+// intBase returns the base and digits of an integer literal, or 0 if s
+// looks like a float.
+func intBase(s string) (int, string) {
+	for prefix, base := range map[string]int{"0x": 16, "0o": 8, "0b": 2} {
+		if strings.HasPrefix(s, prefix) {
+			return base, s[2:]
+		}
+	}
+	if strings.ContainsAny(s, ".eE") {
+		return 0, ""
+	}
+	return 10, s
+}
+
+func (p *parser) parseArray() (*Value, error) {
+	v := &Value{Line: p.line, kind: KindArray}
+	p.pos++ // '['
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, Errorf(v.Line, "unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return v, nil
+		}
+		elem, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		v.array = append(v.array, elem)
+		p.skipBlank(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return v, nil
+		default:
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+	}
+}
+
+func (p *parser) parseInlineTable() (*Value, error) {
+	t := newTable(p.line)
+	t.defined = true
+	v := &Value{Line: p.line, kind: KindTable, table: t}
+	p.pos++ // '{'
+	p.skipBlank(false)
+	if p.peek() == '}' {
+		p.pos++
+		return v, nil
+	}
+	for {
+		p.skipBlank(false)
+		if err := p.parseKeyValue(t); err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return v, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table")
+		}
+	}
+}
+
+func (p *parser) parseBasicString() (string, error) {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		return p.parseMultilineString(`"""`, true)
+	}
+	p.pos++ // opening quote
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		if c == '"' {
+			p.pos++
+			return b.String(), nil
+		}
+		if c == '\\' {
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *parser) parseLiteralString() (string, error) {
+	if strings.HasPrefix(p.src[p.pos:], "'''") {
+		return p.parseMultilineString("'''", false)
+	}
+	p.pos++ // opening quote
+	start := p.pos
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		if p.peek() == '\'' {
+			s := p.src[start:p.pos]
+			p.pos++
+			return s, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseMultilineString(delim string, escapes bool) (string, error) {
+	line := p.line
+	p.pos += len(delim)
+	// A newline immediately after the opening delimiter is trimmed.
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+		p.line++
+	} else if p.peek() == '\n' {
+		p.pos++
+		p.line++
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", Errorf(line, "unterminated multi-line string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], delim) {
+			p.pos += len(delim)
+			return b.String(), nil
+		}
+		c := p.peek()
+		if c == '\\' && escapes {
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if c == '\n' {
+			p.line++
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *parser) parseEscape(b *strings.Builder) error {
+	p.pos++ // backslash
+	if p.eof() {
+		return p.errorf("unterminated escape sequence")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return p.errorf("short unicode escape")
+		}
+		r, err := strconv.ParseUint(p.src[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return p.errorf("invalid unicode escape %q", p.src[p.pos-2:p.pos+n])
+		}
+		b.WriteRune(rune(r))
+		p.pos += n
+	case '\n':
+		// Line-ending backslash in a multi-line string: skip the newline
+		// and any leading whitespace on the next line.
+		p.line++
+		for !p.eof() && strings.ContainsRune(" \t\r\n", rune(p.peek())) {
+			if p.peek() == '\n' {
+				p.line++
+			}
+			p.pos++
+		}
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package toml
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseStrings(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"basic", `k = "hello"`, "hello"},
+		{"literal", `k = 'C:\path\to'`, `C:\path\to`},
+		{"escapes", `k = "a\tb\nc\"d\\e"`, "a\tb\nc\"d\\e"},
+		{"unicode", `k = "caf\u00e9 \U0001F600"`, "café 😀"},
+		{"multiline trims first newline", "k = \"\"\"\nline one\nline two\"\"\"", "line one\nline two"},
+		{"line-ending backslash", "k = \"\"\"\nfoo \\\n    bar\"\"\"", "foo bar"},
+		{"multiline literal", "k = '''\nno \\n escapes'''", `no \n escapes`},
+		{"hash inside string", `k = "a # b" # comment`, "a # b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := Parse([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := root.String("k")
+			if err != nil {
+				t.Fatalf("String: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	root, err := Parse([]byte(`
+int = 1_000
+hex = 0xff
+neg = -3
+float = 2.5e1
+yes = true
+no = false
+list = ["a", 'b',
+  "c", # trailing comma and comment
+]
+inline = { x = 1, y = "two" }
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	for key, want := range map[string]int64{"int": 1000, "hex": 255, "neg": -3} {
+		if got, err := root.Get(key).AsInt(); err != nil || got != want {
+			t.Errorf("%s = %d, %v; want %d", key, got, err, want)
+		}
+	}
+	if got, err := root.Get("float").AsFloat(); err != nil || got != 25 {
+		t.Errorf("float = %v, %v; want 25", got, err)
+	}
+	if got, _ := root.Get("yes").AsBool(); !got {
+		t.Errorf("yes = false, want true")
+	}
+	if got, _ := root.Get("no").AsBool(); got {
+		t.Errorf("no = true, want false")
+	}
+	if got, err := root.Strings("list"); err != nil || !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("list = %q, %v", got, err)
+	}
+	inline, err := root.Table("inline")
+	if err != nil {
+		t.Fatalf("inline: %v", err)
+	}
+	if got := inline.Keys(); !slices.Equal(got, []string{"x", "y"}) {
+		t.Errorf("inline keys = %q", got)
+	}
+}
+
+func TestParseTables(t *testing.T) {
+	root, err := Parse([]byte(`
+top = 1
+
+[[rule]]
+path = "~/work"
+
+[[rule]]
+path = "~/oss"
+color.name = "red"
+
+[policy]
+reserved = ["red"]
+
+[policy.caps]
+blue = 2
+
+[a.b]
+c = 1
+[a]
+d = 2
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := root.Keys(); !slices.Equal(got, []string{"top", "rule", "policy", "a"}) {
+		t.Errorf("root keys = %q", got)
+	}
+
+	rules, err := root.Tables("rule")
+	if err != nil {
+		t.Fatalf("rule: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	for i, want := range []string{"~/work", "~/oss"} {
+		if got, _ := rules[i].String("path"); got != want {
+			t.Errorf("rule %d path = %q, want %q", i, got, want)
+		}
+	}
+	// A dotted key creates the intermediate table.
+	color, err := rules[1].Table("color")
+	if err != nil {
+		t.Fatalf("rule color: %v", err)
+	}
+	if got, _ := color.String("name"); got != "red" {
+		t.Errorf("rule color.name = %q, want red", got)
+	}
+
+	policy, _ := root.Table("policy")
+	caps, err := policy.Table("caps")
+	if err != nil {
+		t.Fatalf("policy.caps: %v", err)
+	}
+	if got, _ := caps.Get("blue").AsInt(); got != 2 {
+		t.Errorf("policy.caps.blue = %d, want 2", got)
+	}
+
+	// [a] may be declared after [a.b], which only implied it.
+	a, _ := root.Table("a")
+	if got := a.Keys(); !slices.Equal(got, []string{"b", "d"}) {
+		t.Errorf("a keys = %q", got)
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	root, err := Parse([]byte(`# comment
+name = "x"
+
+[[rule]]
+path = """
+multi
+line"""
+color = "red"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := root.Get("name").Line; got != 2 {
+		t.Errorf("name on line %d, want 2", got)
+	}
+	rules, _ := root.Tables("rule")
+	if got := rules[0].Line; got != 4 {
+		t.Errorf("[[rule]] on line %d, want 4", got)
+	}
+	if got := rules[0].Get("path").Line; got != 5 {
+		t.Errorf("path on line %d, want 5", got)
+	}
+	if got := rules[0].Get("color").Line; got != 8 {
+		t.Errorf("color on line %d, want 8", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+		msg  string
+	}{
+		{"duplicate key", "a = 1\nb = 2\na = 3", 3, `duplicate key "a" (first defined on line 1)`},
+		{"duplicate dotted key", "a.b = 1\na.b = 2", 2, `duplicate key "b"`},
+		{"duplicate table", "[t]\nx = 1\n[t]", 3, `table "t" is already defined on line 1`},
+		{"table over value", "t = 1\n[t]", 2, `key "t" is already defined as integer on line 1`},
+		{"array over table", "[t]\n[[t]]", 2, `key "t" is already defined as table on line 1`},
+		{"dotted key through value", "a = 1\na.b = 2", 2, `key "a" is already defined as integer on line 1`},
+		{"unterminated string", "a = 1\nb = \"open\nc = 2", 2, "unterminated string"},
+		{"unterminated multiline", "a = \"\"\"\nx\ny", 1, "unterminated multi-line string"},
+		{"bad escape", `a = "\q"`, 1, `invalid escape sequence \q`},
+		{"bad unicode", `a = "\uZZZZ"`, 1, "invalid unicode escape"},
+		{"missing value", "a =\n", 1, "missing value"},
+		{"missing equals", "a 1", 1, "expected '=' after key"},
+		{"trailing junk", `a = "x" y`, 1, `unexpected 'y' after value`},
+		{"unclosed header", "[t\nx = 1", 1, `expected "]" to close table header`},
+		{"unterminated array", "a = [1,\n2,\n", 1, "unterminated array"},
+		{"date", "a = 2024-01-02", 1, "dates and times are not supported"},
+		{"inf", "a = inf", 1, `unsupported number "inf"`},
+		{"line after multiline string", "a = \"\"\"\none\ntwo\"\"\"\na = 2", 4, `duplicate key "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.src))
+			var terr *Error
+			if !errors.As(err, &terr) {
+				t.Fatalf("got %v, want a *toml.Error", err)
+			}
+			if terr.Line != tt.line {
+				t.Errorf("error on line %d, want %d (%v)", terr.Line, tt.line, err)
+			}
+			if !strings.Contains(terr.Msg, tt.msg) {
+				t.Errorf("error %q does not contain %q", terr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	root, err := Parse([]byte("name = \"x\"\n\ncolour = \"red\""))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	err = root.CheckKeys("name", "color")
+	var terr *Error
+	if !errors.As(err, &terr) || terr.Line != 3 || !strings.Contains(terr.Msg, `unknown key "colour"`) {
+		t.Errorf("CheckKeys = %v, want unknown key \"colour\" on line 3", err)
+	}
+}
+
+func TestQuoteRoundTrip(t *testing.T) {
+	for _, s := range []string{"", "plain", `C:\dir`, "tab\there", "quote\"d", "new\nline", "bell\x07"} {
+		root, err := Parse([]byte("k = " + Quote(s)))
+		if err != nil {
+			t.Errorf("Parse(%s): %v", Quote(s), err)
+			continue
+		}
+		if got, _ := root.String("k"); got != s {
+			t.Errorf("round trip of %q gave %q", s, got)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package toml implements a small TOML reader for workspace-colours' own
+// config files. It covers the subset of the format we actually use — tables,
+// arrays of tables, dotted keys, strings, numbers, booleans, arrays and inline
+// tables — and keeps the line number of every value so callers can point
+// users at the offending line when validation fails.
+package toml
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind identifies the type of a parsed value.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInteger
+	KindFloat
+	KindBool
+	KindArray
+	KindTable
+	KindTableArray
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInteger:
+		return "integer"
+	case KindFloat:
+		return "float"
+	case KindBool:
+		return "boolean"
+	case KindArray:
+		return "array"
+	case KindTable:
+		return "table"
+	case KindTableArray:
+		return "array of tables"
+	}
+	return "unknown"
+}
+
+// Error is a parse or validation error tied to a line in the source.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// Errorf returns an *Error for the given line.
+func Errorf(line int, format string, args ...any) error {
+	return &Error{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Value is a single parsed TOML value.
+type Value struct {
+	// Line is the line the value starts on (1-based).
+	Line int
+
+	kind   Kind
+	str    string
+	num    int64
+	float  float64
+	bool   bool
+	array  []*Value
+	table  *Table
+	tables []*Table
+}
+
+// Kind returns the value's type.
+func (v *Value) Kind() Kind { return v.kind }
+
+// AsString returns the value as a string.
+func (v *Value) AsString() (string, error) {
+	if v.kind != KindString {
+		return "", v.typeError(KindString)
+	}
+	return v.str, nil
+}
+
+// AsInt returns the value as an integer.
+func (v *Value) AsInt() (int64, error) {
+	if v.kind != KindInteger {
+		return 0, v.typeError(KindInteger)
+	}
+	return v.num, nil
+}
+
+// AsFloat returns the value as a float. Integers are converted.
+func (v *Value) AsFloat() (float64, error) {
+	switch v.kind {
+	case KindFloat:
+		return v.float, nil
+	case KindInteger:
+		return float64(v.num), nil
+	}
+	return 0, v.typeError(KindFloat)
+}
+
+// AsBool returns the value as a boolean.
+func (v *Value) AsBool() (bool, error) {
+	if v.kind != KindBool {
+		return false, v.typeError(KindBool)
+	}
+	return v.bool, nil
+}
+
+// AsArray returns the elements of an array value.
+func (v *Value) AsArray() ([]*Value, error) {
+	if v.kind != KindArray {
+		return nil, v.typeError(KindArray)
+	}
+	return v.array, nil
+}
+
+// AsStrings returns an array value whose elements are all strings.
+func (v *Value) AsStrings() ([]string, error) {
+	elems, err := v.AsArray()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(elems))
+	for i, e := range elems {
+		s, err := e.AsString()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// AsTable returns a table value (a [header] section or an inline table).
+func (v *Value) AsTable() (*Table, error) {
+	if v.kind != KindTable {
+		return nil, v.typeError(KindTable)
+	}
+	return v.table, nil
+}
+
+// AsTables returns the tables of a [[header]] array, or of an inline array
+// whose elements are all inline tables.
+func (v *Value) AsTables() ([]*Table, error) {
+	switch v.kind {
+	case KindTableArray:
+		return v.tables, nil
+	case KindArray:
+		out := make([]*Table, len(v.array))
+		for i, e := range v.array {
+			t, err := e.AsTable()
+			if err != nil {
+				return nil, err
+			}
+			out[i] = t
+		}
+		return out, nil
+	}
+	return nil, v.typeError(KindTableArray)
+}
+
+func (v *Value) typeError(want Kind) error {
+	return Errorf(v.Line, "expected %s, got %s", want, v.kind)
+}
+
+// Table is a set of key/value pairs, kept in source order.
+type Table struct {
+	// Line is the line the table was declared on (0 for the root table).
+	Line int
+
+	keys   []string
+	values map[string]*Value
+	// defined is true once the table has had an explicit [header], so a
+	// second header for the same table can be rejected.
+	defined bool
+}
+
+func newTable(line int) *Table {
+	return &Table{Line: line, values: make(map[string]*Value)}
+}
+
+// Keys returns the table's keys in the order they first appeared.
+func (t *Table) Keys() []string { return t.keys }
+
+// Get returns the value for key, or nil if the key is absent.
+func (t *Table) Get(key string) *Value { return t.values[key] }
+
+// Has reports whether key is present in the table.
+func (t *Table) Has(key string) bool { _, ok := t.values[key]; return ok }
+
+// CheckKeys returns an error for the first key that is not in allowed,
+// which catches typos in hand-written config files.
+func (t *Table) CheckKeys(allowed ...string) error {
+	for _, k := range t.keys {
+		known := false
+		for _, a := range allowed {
+			if k == a {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return Errorf(t.values[k].Line, "unknown key %q", k)
+		}
+	}
+	return nil
+}
+
+// String returns the string at key, or "" if the key is absent.
+func (t *Table) String(key string) (string, error) {
+	v := t.Get(key)
+	if v == nil {
+		return "", nil
+	}
+	return v.AsString()
+}
+
+// Strings returns the string array at key, or nil if the key is absent.
+func (t *Table) Strings(key string) ([]string, error) {
+	v := t.Get(key)
+	if v == nil {
+		return nil, nil
+	}
+	return v.AsStrings()
+}
+
+// Table returns the sub-table at key, or nil if the key is absent.
+func (t *Table) Table(key string) (*Table, error) {
+	v := t.Get(key)
+	if v == nil {
+		return nil, nil
+	}
+	return v.AsTable()
+}
+
+// Tables returns the array of tables at key, or nil if the key is absent.
+func (t *Table) Tables(key string) ([]*Table, error) {
+	v := t.Get(key)
+	if v == nil {
+		return nil, nil
+	}
+	return v.AsTables()
+}
+
+func (t *Table) set(key string, v *Value) error {
+	if prev, ok := t.values[key]; ok {
+		return Errorf(v.Line, "duplicate key %q (first defined on line %d)", key, prev.Line)
+	}
+	t.keys = append(t.keys, key)
+	t.values[key] = v
+	return nil
+}
+
+// Quote returns s as a TOML basic string, for callers that write config files.
+func Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}